@@ -1,9 +1,15 @@
 package main
 
 import (
+	"apiwatcher/internal/alert"
+	"apiwatcher/internal/artifact"
 	"apiwatcher/internal/config"
 	"apiwatcher/internal/daemon"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/models"
 	"apiwatcher/internal/remote"
+	"apiwatcher/internal/schedule"
+	"apiwatcher/internal/secrets"
 	"apiwatcher/internal/snapshot"
 	"encoding/json"
 	"fmt"
@@ -25,6 +31,9 @@ type App struct {
 	cachedWebsiteStats []daemon.WebsiteStatsResponse
 	preferences        *AppPreferences
 	activeRecordings   map[string]chan bool
+	recordingInserts   map[string]chan models.SnapshotAction // Actions inserted into active recordings
+	recordingDone      map[string]chan *snapshot.Snapshot    // Receives the saved snapshot (nil on failure)
+	recordedPasswords  map[string]*snapshot.Snapshot         // Finished recordings with passwords in clear, saved redacted
 	recordingsMux      sync.Mutex
 }
 
@@ -57,9 +66,10 @@ type ProfileInfo struct {
 }
 
 type ConfigInfo struct {
-	Name  string   `json:"name"`
-	URLs  []string `json:"urls"`
-	Error string   `json:"error,omitempty"`
+	Name    string          `json:"name"`
+	URLs    []string        `json:"urls"`
+	Targets []config.Target `json:"targets"`
+	Error   string          `json:"error,omitempty"`
 }
 
 type WebsiteStats struct {
@@ -85,15 +95,42 @@ type DashboardData struct {
 type SnapshotInfo struct {
 	ID        string `json:"id"`
 	URL       string `json:"url"`
+	Name      string `json:"name"`
+	Version   int    `json:"version"`
 	CreatedAt string `json:"created_at"`
 	Actions   int    `json:"actions"`
 }
 
+// newSnapshotInfo summarizes a snapshot for the UI
+func newSnapshotInfo(snap *snapshot.Snapshot) SnapshotInfo {
+	return SnapshotInfo{
+		ID:        snap.ID,
+		URL:       snap.URL,
+		Name:      snap.Name,
+		Version:   snap.Version,
+		CreatedAt: snap.CreatedAt.Format("2006-01-02 15:04:05"),
+		Actions:   len(snap.Actions),
+	}
+}
+
+// RecordingResult is the snapshot saved by a finished recording
+type RecordingResult struct {
+	Snapshot       SnapshotInfo             `json:"snapshot"`
+	PasswordInputs []snapshot.PasswordInput `json:"password_inputs"` // Passwords left out of the saved snapshot, to store as secrets or keep
+}
+
+// recordingSaveTimeout bounds how long FinishRecording waits for the snapshot to be saved
+const recordingSaveTimeout = 30 * time.Second
+
 // NewApp creates a new App instance
 func NewApp() *App {
 	app := &App{
 		preferences:      &AppPreferences{},
 		activeRecordings: make(map[string]chan bool),
+		recordingInserts: make(map[string]chan models.SnapshotAction),
+		recordingDone:    make(map[string]chan *snapshot.Snapshot),
+
+		recordedPasswords: make(map[string]*snapshot.Snapshot),
 	}
 	app.loadPreferences()
 	return app
@@ -370,21 +407,17 @@ func (a *App) StartMonitoring(monitoringConfigRaw interface{}) error {
 
 	// Convert interface{} to map[string]interface{} with snapshot preferences
 	monitoringConfig := make(map[string]bool) // URL -> enableSnapshots
-	websites := []string{}
+	websites := []config.Target{}
 
 	switch v := monitoringConfigRaw.(type) {
 	case map[string]interface{}:
-		for url, config := range v {
-			websites = append(websites, url)
-
-			// Check if snapshots should be enabled for this URL
-			if configMap, ok := config.(map[string]interface{}); ok {
-				if enableSnaps, exists := configMap["enableSnapshots"]; exists {
-					if enable, ok := enableSnaps.(bool); ok {
-						monitoringConfig[url] = enable
-					}
-				}
+		for url, options := range v {
+			target, enableSnapshots, err := parseMonitoringTarget(url, options)
+			if err != nil {
+				return fmt.Errorf("invalid options for %s: %w", url, err)
 			}
+			websites = append(websites, target)
+			monitoringConfig[url] = enableSnapshots
 		}
 	default:
 		return fmt.Errorf("invalid monitoring config format")
@@ -421,6 +454,84 @@ func (a *App) StartMonitoring(monitoringConfigRaw interface{}) error {
 	return nil
 }
 
+// parseMonitoringTarget builds a target from the per-URL options sent by the frontend.
+// Options use the config.Target JSON fields plus "enableSnapshots" and the camelCase "httpProbe".
+func parseMonitoringTarget(url string, raw interface{}) (config.Target, bool, error) {
+	target := config.Target{URL: url}
+	enableSnapshots := false
+
+	options, ok := raw.(map[string]interface{})
+	if !ok {
+		return target, enableSnapshots, nil
+	}
+
+	// Check if snapshots should be enabled for this URL
+	if enable, ok := options["enableSnapshots"].(bool); ok {
+		enableSnapshots = enable
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return target, enableSnapshots, err
+	}
+	if err := json.Unmarshal(data, &target); err != nil {
+		return target, enableSnapshots, err
+	}
+	target.URL = url
+
+	// Optional lightweight HTTP probe instead of the browser check
+	if rawProbe, exists := options["httpProbe"]; exists && rawProbe != nil {
+		probeData, err := json.Marshal(rawProbe)
+		if err != nil {
+			return target, enableSnapshots, err
+		}
+		var probe config.HTTPProbe
+		if err := json.Unmarshal(probeData, &probe); err != nil {
+			return target, enableSnapshots, err
+		}
+		target.HTTPProbe = &probe
+		if target.CheckType == "" {
+			target.CheckType = config.CheckModeHTTP
+		}
+	}
+
+	if err := config.ValidateTarget(target); err != nil {
+		return target, enableSnapshots, err
+	}
+	return target, enableSnapshots, nil
+}
+
+// parseTargets converts a list of URL strings or target objects from the frontend into targets
+func parseTargets(raw interface{}) ([]config.Target, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var targets []config.Target
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("invalid targets: %w", err)
+	}
+	for _, target := range targets {
+		if err := config.ValidateTarget(target); err != nil {
+			return nil, fmt.Errorf("invalid target %s: %w", target.URL, err)
+		}
+	}
+	return targets, nil
+}
+
+// GetMonitoringTargets returns the targets the daemon is currently configured to monitor
+func (a *App) GetMonitoringTargets() ([]config.Target, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	cfg, err := a.daemonClient.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Websites, nil
+}
+
 func (a *App) StopMonitoring() error {
 	if a.daemonClient == nil {
 		return fmt.Errorf("not connected to daemon")
@@ -597,8 +708,9 @@ func (a *App) ListConfigs() ([]ConfigInfo, error) {
 	var result []ConfigInfo
 	for _, cfg := range configs {
 		result = append(result, ConfigInfo{
-			Name: cfg.Name,
-			URLs: cfg.Websites,
+			Name:    cfg.Name,
+			URLs:    config.TargetURLs(cfg.Websites),
+			Targets: cfg.Websites,
 		})
 	}
 	return result, nil
@@ -614,8 +726,9 @@ func (a *App) LoadConfig(name string) (*ConfigInfo, error) {
 	for _, cfg := range configs {
 		if cfg.Name == name {
 			return &ConfigInfo{
-				Name: cfg.Name,
-				URLs: cfg.Websites,
+				Name:    cfg.Name,
+				URLs:    config.TargetURLs(cfg.Websites),
+				Targets: cfg.Websites,
 			}, nil
 		}
 	}
@@ -624,24 +737,24 @@ func (a *App) LoadConfig(name string) (*ConfigInfo, error) {
 }
 
 // SaveConfig saves a configuration
+// urls may be a list of URL strings or of target objects (name, tags, owner, check_type, ...)
 func (a *App) SaveConfig(name string, urls interface{}) error {
-	// Convert []interface{} to []string
-	var urlsList []string
+	var targets []config.Target
 
 	switch v := urls.(type) {
+	case []string:
+		targets = config.TargetsFromURLs(v)
 	case []interface{}:
-		for _, u := range v {
-			if str, ok := u.(string); ok {
-				urlsList = append(urlsList, str)
-			}
+		parsed, err := parseTargets(v)
+		if err != nil {
+			return err
 		}
-	case []string:
-		urlsList = v
+		targets = parsed
 	default:
 		return fmt.Errorf("invalid urls type")
 	}
 
-	return config.SaveMonitorConfig(name, "", urlsList, map[string]string{})
+	return config.SaveMonitorConfig(name, "", targets, map[string]string{})
 }
 
 // CreateNewConfig creates a new configuration
@@ -665,12 +778,7 @@ func (a *App) ListSnapshots(url string) ([]SnapshotInfo, error) {
 
 	var result []SnapshotInfo
 	for _, snap := range snapshots {
-		result = append(result, SnapshotInfo{
-			ID:        snap.ID,
-			URL:       snap.URL,
-			CreatedAt: snap.CreatedAt.Format("2006-01-02 15:04:05"),
-			Actions:   len(snap.Actions),
-		})
+		result = append(result, newSnapshotInfo(snap))
 	}
 	return result, nil
 }
@@ -680,46 +788,99 @@ func (a *App) ListSnapshots(url string) ([]SnapshotInfo, error) {
 func (a *App) StartRecording(url string) (string, error) {
 	// Create a stop channel for this recording
 	stopChan := make(chan bool, 1)
+	inserts := make(chan models.SnapshotAction, 16)
+	done := make(chan *snapshot.Snapshot, 1)
 	recordingID := fmt.Sprintf("%d", time.Now().UnixNano())
 
-	// Store the recording channel
+	// Store the recording channels
 	a.recordingsMux.Lock()
 	a.activeRecordings[recordingID] = stopChan
+	a.recordingInserts[recordingID] = inserts
+	a.recordingDone[recordingID] = done
 	a.recordingsMux.Unlock()
 
 	// Start recording in a goroutine
 	go func() {
-		snap, err := snapshot.RecordWithCallback(url, "GUI Snapshot", stopChan)
+		snap, err := snapshot.RecordWithInserts(url, "GUI Snapshot", stopChan, inserts)
+
+		// Clean up
+		a.recordingsMux.Lock()
+		delete(a.activeRecordings, recordingID)
+		delete(a.recordingInserts, recordingID)
+		delete(a.recordingDone, recordingID)
+		a.recordingsMux.Unlock()
+
 		if err != nil {
 			log.Printf("[RECORDING] Failed to record: %v", err)
+			done <- nil
 			return
 		}
 
 		// Snapshot is automatically saved by RecordWithCallback
 		log.Printf("[RECORDING] Snapshot saved: %s", snap.ID)
-
-		// Clean up
-		a.recordingsMux.Lock()
-		delete(a.activeRecordings, recordingID)
-		a.recordingsMux.Unlock()
+		done <- snap
 	}()
 
 	return recordingID, nil
 }
 
-// FinishRecording stops a recording session
-func (a *App) FinishRecording(recordingID string) error {
+// FinishRecording stops a recording session and returns the saved snapshot with
+// the password inputs it recorded. They are saved redacted; the UI offers to
+// store them as secrets (StorePasswordAsSecret) or keep them (KeepRecordedPasswords).
+func (a *App) FinishRecording(recordingID string) (*RecordingResult, error) {
 	a.recordingsMux.Lock()
 	stopChan, exists := a.activeRecordings[recordingID]
+	done := a.recordingDone[recordingID]
 	a.recordingsMux.Unlock()
 
 	if !exists {
-		return fmt.Errorf("recording not found: %s", recordingID)
+		return nil, fmt.Errorf("recording not found: %s", recordingID)
 	}
 
 	// Send stop signal
 	stopChan <- false // false = normal stop, true = cancel
-	return nil
+
+	select {
+	case snap := <-done:
+		if snap == nil {
+			return nil, fmt.Errorf("failed to save the recording, see the logs")
+		}
+		inputs := snap.PasswordInputs()
+		if len(inputs) > 0 {
+			a.recordingsMux.Lock()
+			a.recordedPasswords[snap.ID] = snap
+			a.recordingsMux.Unlock()
+		}
+		return &RecordingResult{
+			Snapshot:       newSnapshotInfo(snap),
+			PasswordInputs: inputs,
+		}, nil
+	case <-time.After(recordingSaveTimeout):
+		return nil, fmt.Errorf("timed out waiting for the recording to be saved")
+	}
+}
+
+// AddRecordingAssertion inserts an assertion action (assert_text, assert_visible, ...)
+// at the current position of an active recording. The recorder adds the locators
+// of the element the selector matches.
+func (a *App) AddRecordingAssertion(recordingID string, action models.SnapshotAction) error {
+	if err := snapshot.ValidateAssertion(action); err != nil {
+		return err
+	}
+
+	a.recordingsMux.Lock()
+	inserts, exists := a.recordingInserts[recordingID]
+	a.recordingsMux.Unlock()
+	if !exists {
+		return fmt.Errorf("recording not found: %s", recordingID)
+	}
+
+	select {
+	case inserts <- action:
+		return nil
+	default:
+		return fmt.Errorf("recording is busy, try again")
+	}
 }
 
 // CreateSnapshot creates a new instant snapshot for a URL (non-interactive)
@@ -733,12 +894,8 @@ func (a *App) CreateSnapshot(url string) (*SnapshotInfo, error) {
 		return nil, fmt.Errorf("failed to create snapshot: %w", err)
 	}
 
-	return &SnapshotInfo{
-		ID:        snap.ID,
-		URL:       snap.URL,
-		CreatedAt: snap.CreatedAt.Format("2006-01-02 15:04:05"),
-		Actions:   len(snap.Actions),
-	}, nil
+	info := newSnapshotInfo(snap)
+	return &info, nil
 }
 
 // DeleteSnapshot deletes a snapshot by ID
@@ -761,6 +918,218 @@ func (a *App) ReplaySnapshot(snapshotID string) error {
 	return snapshot.Replay(snap)
 }
 
+// ============ SNAPSHOT EDITING ============
+
+// GetSnapshot returns a snapshot with all its actions, for editing
+func (a *App) GetSnapshot(snapshotID string) (*snapshot.Snapshot, error) {
+	snap, err := snapshot.LoadByID(snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// Every edit is saved as a new snapshot version; note is the author's optional
+// reason for it, shown in the version history
+
+// RenameSnapshot sets the display name of a snapshot
+func (a *App) RenameSnapshot(snapshotID, name, note string) (*snapshot.Snapshot, error) {
+	return snapshot.Edit(snapshotID, fmt.Sprintf("Renamed to %q", name), note, func(snap *snapshot.Snapshot) error {
+		return snap.Rename(name)
+	})
+}
+
+// UpdateSnapshotAction replaces an action, e.g. to fix a selector that changed
+func (a *App) UpdateSnapshotAction(snapshotID string, index int, action models.SnapshotAction, note string) (*snapshot.Snapshot, error) {
+	change := fmt.Sprintf("Updated action %d (%s)", index+1, action.Type)
+	return snapshot.Edit(snapshotID, change, note, func(snap *snapshot.Snapshot) error {
+		return snap.UpdateAction(index, action)
+	})
+}
+
+// InsertSnapshotAction inserts an action (a wait, an assertion, ...) before index;
+// an index equal to the number of actions appends it
+func (a *App) InsertSnapshotAction(snapshotID string, index int, action models.SnapshotAction, note string) (*snapshot.Snapshot, error) {
+	change := fmt.Sprintf("Inserted action %d (%s)", index+1, action.Type)
+	return snapshot.Edit(snapshotID, change, note, func(snap *snapshot.Snapshot) error {
+		return snap.InsertAction(index, action)
+	})
+}
+
+// DeleteSnapshotAction removes an action
+func (a *App) DeleteSnapshotAction(snapshotID string, index int, note string) (*snapshot.Snapshot, error) {
+	return snapshot.Edit(snapshotID, fmt.Sprintf("Deleted action %d", index+1), note, func(snap *snapshot.Snapshot) error {
+		return snap.DeleteAction(index)
+	})
+}
+
+// MoveSnapshotAction moves an action from one position to another
+func (a *App) MoveSnapshotAction(snapshotID string, from, to int, note string) (*snapshot.Snapshot, error) {
+	change := fmt.Sprintf("Moved action %d to %d", from+1, to+1)
+	return snapshot.Edit(snapshotID, change, note, func(snap *snapshot.Snapshot) error {
+		return snap.MoveAction(from, to)
+	})
+}
+
+// ============ SNAPSHOT VERSIONS ============
+
+// GetSnapshotVersions lists the saved versions of a snapshot, newest first
+func (a *App) GetSnapshotVersions(snapshotID string) ([]snapshot.Version, error) {
+	return snapshot.ListVersions(snapshotID)
+}
+
+// GetSnapshotVersion returns a snapshot as it was saved in a version
+func (a *App) GetSnapshotVersion(snapshotID string, version int) (*snapshot.Snapshot, error) {
+	return snapshot.LoadVersion(snapshotID, version)
+}
+
+// DiffSnapshotVersions compares two versions of a snapshot action by action
+func (a *App) DiffSnapshotVersions(snapshotID string, from, to int) (*snapshot.Diff, error) {
+	return snapshot.DiffVersions(snapshotID, from, to)
+}
+
+// RollbackSnapshot restores an earlier version, saved as the newest version
+func (a *App) RollbackSnapshot(snapshotID string, version int, note string) (*snapshot.Snapshot, error) {
+	return snapshot.Rollback(snapshotID, version, note)
+}
+
+// ============ SNAPSHOT IMPORT & EXPORT ============
+
+// ExportSnapshots exports snapshots (all if ids is empty) as a bundle to share with
+// another machine; passwords typed in clear are left out
+func (a *App) ExportSnapshots(ids []string) ([]byte, error) {
+	snaps, err := snapshot.LoadByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.ExportBundle(snaps)
+}
+
+// ImportSnapshots imports a bundle, a Chrome DevTools Recorder JSON export or a
+// Playwright/Puppeteer script (format is "bundle", "chrome_recorder" or "script").
+// With overwrite, bundled snapshots replace existing ones with the same ID as a
+// new version; otherwise they are imported under a new ID.
+func (a *App) ImportSnapshots(format string, data []byte, overwrite bool) (*snapshot.ImportResult, error) {
+	return snapshot.Import(format, data, overwrite)
+}
+
+// PushSnapshotsToDaemon copies snapshots (all if ids is empty) to the connected daemon
+func (a *App) PushSnapshotsToDaemon(ids []string, overwrite bool) (*snapshot.ImportResult, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	bundle, err := a.ExportSnapshots(ids)
+	if err != nil {
+		return nil, err
+	}
+	return a.daemonClient.ImportSnapshots(snapshot.FormatBundle, bundle, overwrite)
+}
+
+// PullSnapshotsFromDaemon copies the connected daemon's snapshots (all if ids is empty) here
+func (a *App) PullSnapshotsFromDaemon(ids []string, overwrite bool) (*snapshot.ImportResult, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	bundle, err := a.daemonClient.ExportSnapshots(ids)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Import(snapshot.FormatBundle, bundle, overwrite)
+}
+
+// ============ SNAPSHOT SECRETS ============
+
+// StorePasswordAsSecret moves a recorded password (see RecordingResult) to the
+// encrypted secret store and makes the snapshot reference it as {{secret.name}}
+func (a *App) StorePasswordAsSecret(snapshotID string, actionIndex int, name string) error {
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return fmt.Errorf("failed to open secret store: %w", err)
+	}
+
+	// A recording that just finished was saved redacted, its passwords are only here
+	a.recordingsMux.Lock()
+	defer a.recordingsMux.Unlock()
+	recorded := a.recordedPasswords[snapshotID]
+
+	_, err = snapshot.Edit(snapshotID, "Stored password as "+snapshot.SecretRef(name), "", func(snap *snapshot.Snapshot) error {
+		if actionIndex < 0 || actionIndex >= len(snap.Actions) {
+			return fmt.Errorf("invalid action index: %d", actionIndex)
+		}
+		if recorded != nil {
+			if actionIndex >= len(recorded.Actions) {
+				return fmt.Errorf("the snapshot changed since it was recorded")
+			}
+			snap.Actions[actionIndex].Value = recorded.Actions[actionIndex].Value
+		}
+		password, err := snap.ReplaceWithSecret(actionIndex, name)
+		if err != nil {
+			return err
+		}
+		// Store first so the snapshot never references a secret that wasn't saved
+		if err := store.Set(name, password); err != nil {
+			return fmt.Errorf("failed to store secret: %w", err)
+		}
+		if recorded != nil {
+			// Keep the indexes of the recording in step with the snapshot's
+			_, _ = recorded.ReplaceWithSecret(actionIndex, name)
+		}
+		return nil
+	})
+	return err
+}
+
+// KeepRecordedPasswords saves the passwords of a finished recording that weren't
+// stored as secrets in the snapshot, in clear
+func (a *App) KeepRecordedPasswords(snapshotID string) error {
+	a.recordingsMux.Lock()
+	defer a.recordingsMux.Unlock()
+	recorded, exists := a.recordedPasswords[snapshotID]
+	if !exists {
+		return fmt.Errorf("no recorded passwords for snapshot %s", snapshotID)
+	}
+
+	_, err := snapshot.Edit(snapshotID, "Kept recorded passwords", "", func(snap *snapshot.Snapshot) error {
+		if snapshot.RestoreRedacted(snap, recorded) == 0 {
+			return fmt.Errorf("the snapshot changed since it was recorded, its passwords can't be restored")
+		}
+		return nil
+	})
+	if err == nil {
+		delete(a.recordedPasswords, snapshotID)
+	}
+	return err
+}
+
+// ListSecrets returns the names of the stored secrets (values are never returned)
+func (a *App) ListSecrets() ([]string, error) {
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secret store: %w", err)
+	}
+	return store.Names(), nil
+}
+
+// SetSecret stores a secret for {{secret.name}} placeholders, replacing any existing value
+func (a *App) SetSecret(name, value string) error {
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return fmt.Errorf("failed to open secret store: %w", err)
+	}
+	return store.Set(name, value)
+}
+
+// DeleteSecret removes a stored secret
+func (a *App) DeleteSecret(name string) error {
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return fmt.Errorf("failed to open secret store: %w", err)
+	}
+	return store.Delete(name)
+}
+
 // ============ SMTP CONFIGURATION ============
 
 // ConfigureSMTP updates SMTP configuration
@@ -819,6 +1188,195 @@ func (a *App) GetSMTPConfig() (map[string]interface{}, error) {
 	return result, nil
 }
 
+// ============ WEBHOOK CONFIGURATION ============
+
+// SaveWebhook adds or updates a webhook alert channel
+// Leaving the secret empty keeps the secret already stored on the daemon
+func (a *App) SaveWebhook(raw interface{}) error {
+	if a.daemonClient == nil {
+		return fmt.Errorf("not connected to daemon")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var webhook config.WebhookConfig
+	if err := json.Unmarshal(data, &webhook); err != nil {
+		return fmt.Errorf("invalid webhook: %w", err)
+	}
+	if err := config.ValidateWebhookConfig(&webhook); err != nil {
+		return err
+	}
+
+	return a.daemonClient.SetWebhook(webhook)
+}
+
+// GetWebhooks returns the configured webhooks (without secrets)
+func (a *App) GetWebhooks() ([]daemon.WebhookInfo, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.GetWebhooks()
+}
+
+// DeleteWebhook removes a webhook by name
+func (a *App) DeleteWebhook(name string) error {
+	if a.daemonClient == nil {
+		return fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.DeleteWebhook(name)
+}
+
+// TestWebhook sends a test alert to a webhook
+func (a *App) TestWebhook(name string) error {
+	if a.daemonClient == nil {
+		return fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.TestWebhook(name)
+}
+
+// ============ ALERT ACKNOWLEDGEMENT & SILENCING ============
+
+// AcknowledgeAlert acknowledges the open incident for a website, stopping repeats and escalation
+func (a *App) AcknowledgeAlert(target string) (*alert.Incident, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	by, _ := os.Hostname()
+	return a.daemonClient.AckAlert(target, by)
+}
+
+// GetIncidents returns the open alert incidents
+func (a *App) GetIncidents() ([]alert.Incident, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.ListIncidents()
+}
+
+// SilenceAlerts suppresses alerts for a website ("*" for all) for the given number of minutes
+func (a *App) SilenceAlerts(target string, minutes int, reason string) (*alert.Silence, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.Silence(target, time.Duration(minutes)*time.Minute, reason)
+}
+
+// RemoveSilence removes a silence by ID
+func (a *App) RemoveSilence(id string) error {
+	if a.daemonClient == nil {
+		return fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.Unsilence(id)
+}
+
+// GetSilences returns the active silences
+func (a *App) GetSilences() ([]alert.Silence, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.ListSilences()
+}
+
+// ============ MAINTENANCE WINDOWS ============
+
+// SaveMaintenanceWindow adds or updates a maintenance window
+// Start and end are RFC 3339 timestamps; recurrence is "" (one-off) or "weekly"
+func (a *App) SaveMaintenanceWindow(raw interface{}) (*schedule.MaintenanceWindow, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var window schedule.MaintenanceWindow
+	if err := json.Unmarshal(data, &window); err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: %w", err)
+	}
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+
+	return a.daemonClient.SetMaintenanceWindow(window)
+}
+
+// GetMaintenanceWindows returns the configured maintenance windows
+func (a *App) GetMaintenanceWindows() ([]schedule.MaintenanceWindow, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.ListMaintenanceWindows()
+}
+
+// DeleteMaintenanceWindow removes a maintenance window by ID
+func (a *App) DeleteMaintenanceWindow(id string) error {
+	if a.daemonClient == nil {
+		return fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.DeleteMaintenanceWindow(id)
+}
+
+// ============ CHECK HISTORY ============
+
+// GetHistory returns a website's check history between from and to (RFC 3339, empty = last 24 hours).
+// Resolution is a bucket size such as "5m" or "1h"; empty or "raw" returns every check.
+func (a *App) GetHistory(target, from, to, resolution string) (*daemon.HistoryData, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+
+	var fromTime, toTime time.Time
+	var err error
+	if from != "" {
+		if fromTime, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, fmt.Errorf("invalid from time: %w", err)
+		}
+	}
+	if to != "" {
+		if toTime, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, fmt.Errorf("invalid to time: %w", err)
+		}
+	}
+
+	return a.daemonClient.GetHistory(target, fromTime, toTime, resolution)
+}
+
+// GetHARRecordings lists the recorded HAR sessions of a website (every website if empty), newest first
+func (a *App) GetHARRecordings(target string) ([]har.Info, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.ListHAR(target)
+}
+
+// GetHAR returns a recorded HAR session with a file name to download it as
+func (a *App) GetHAR(id string) (*daemon.HARData, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.GetHAR(id)
+}
+
+// GetReplayArtifacts lists the screenshots and DOMs captured at failed replay steps of a website (every website if empty)
+func (a *App) GetReplayArtifacts(target string) ([]artifact.Info, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.ListArtifacts(target)
+}
+
+// GetReplayArtifact returns a failure artifact with its screenshot and DOM
+func (a *App) GetReplayArtifact(id string) (*daemon.ArtifactData, error) {
+	if a.daemonClient == nil {
+		return nil, fmt.Errorf("not connected to daemon")
+	}
+	return a.daemonClient.GetArtifact(id)
+}
+
 // ============ UTILITIES ============
 
 // Ping tests connection to daemon
@@ -2,6 +2,8 @@ package main
 
 import (
 	"apiwatcher/internal/daemon"
+	"apiwatcher/internal/tracing"
+	"context"
 	"flag"
 	"fmt"
 	"log"
@@ -20,6 +22,9 @@ func main() {
 	// Command line flags
 	dataDir := flag.String("data-dir", getDefaultDataDir(), "Data directory for daemon state and logs")
 	port := flag.String("port", "9876", "Port to listen on (localhost only)")
+	historyDays := flag.Int("history-retention-days", 90, "Days of check history to keep")
+	metricsAddr := flag.String("metrics-addr", "", "Address to serve Prometheus /metrics on, e.g. :9877 (disabled if empty)")
+	otlpEndpoint := flag.String("otlp-endpoint", tracing.EndpointFromEnv(), "OTLP/HTTP collector URL to export check traces to, e.g. http://localhost:4318 (disabled if empty)")
 	version := flag.Bool("version", false, "Print version and exit")
 	flag.Parse()
 
@@ -32,11 +37,23 @@ func main() {
 	log.Printf("Data directory: %s", *dataDir)
 
 	// Create daemon
-	d, err := daemon.New(*dataDir)
+	d, err := daemon.New(*dataDir, time.Duration(*historyDays)*24*time.Hour)
 	if err != nil {
 		log.Fatalf("Failed to create daemon: %v", err)
 	}
 
+	// Optional trace export
+	var exporter *tracing.Exporter
+	if *otlpEndpoint != "" {
+		serviceName := os.Getenv("OTEL_SERVICE_NAME")
+		if serviceName == "" {
+			serviceName = "apiwatcher-daemon"
+		}
+		exporter = tracing.NewExporter(*otlpEndpoint, serviceName)
+		tracing.SetExporter(exporter)
+		log.Printf("Exporting traces to %s", exporter.Endpoint)
+	}
+
 	// Create and start server
 	address := fmt.Sprintf("localhost:%s", *port)
 	server := daemon.NewServer(d, address)
@@ -45,6 +62,15 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 
+	// Optional Prometheus metrics endpoint
+	var metricsServer *daemon.MetricsServer
+	if *metricsAddr != "" {
+		metricsServer = daemon.NewMetricsServer(d, *metricsAddr)
+		if err := metricsServer.Start(); err != nil {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}
+
 	log.Printf("Daemon is running")
 
 	// Wait for interrupt signal
@@ -75,6 +101,16 @@ func main() {
 	// Stop server
 	log.Println("Stopping server...")
 	server.Stop()
+	if metricsServer != nil {
+		metricsServer.Stop()
+	}
+	if exporter != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := exporter.Shutdown(ctx); err != nil {
+			log.Printf("Error flushing traces: %v", err)
+		}
+		cancel()
+	}
 
 	log.Println("Daemon stopped")
 }
@@ -19,6 +19,7 @@ export const api = {
   clearLogs: () => window.backend.App.ClearLogs(),
   startMonitoring: (websites) => window.backend.App.StartMonitoring(websites),
   stopMonitoring: () => window.backend.App.StopMonitoring(),
+  getMonitoringTargets: () => window.backend.App.GetMonitoringTargets(),
 
   // Configuration
   listConfigs: () => window.backend.App.ListConfigs(),
@@ -32,15 +33,106 @@ export const api = {
   createSnapshot: (url) => window.backend.App.CreateSnapshot(url),
   startRecording: (url) => window.backend.App.StartRecording(url),
   finishRecording: (recordingId) => window.backend.App.FinishRecording(recordingId),
+  addRecordingAssertion: (recordingId, assertion) =>
+    window.backend.App.AddRecordingAssertion(recordingId, assertion),
   deleteSnapshot: (id) => window.backend.App.DeleteSnapshot(id),
   replaySnapshot: (id) => window.backend.App.ReplaySnapshot(id),
 
+  // Snapshot editing (each saves a new version with the optional note and returns the snapshot)
+  getSnapshot: (id) => window.backend.App.GetSnapshot(id),
+  renameSnapshot: (id, name, note) => window.backend.App.RenameSnapshot(id, name, note || ''),
+  updateSnapshotAction: (id, index, action, note) =>
+    window.backend.App.UpdateSnapshotAction(id, index, action, note || ''),
+  insertSnapshotAction: (id, index, action, note) =>
+    window.backend.App.InsertSnapshotAction(id, index, action, note || ''),
+  deleteSnapshotAction: (id, index, note) => window.backend.App.DeleteSnapshotAction(id, index, note || ''),
+  moveSnapshotAction: (id, from, to, note) => window.backend.App.MoveSnapshotAction(id, from, to, note || ''),
+
+  // Snapshot versions
+  getSnapshotVersions: (id) => window.backend.App.GetSnapshotVersions(id),
+  getSnapshotVersion: (id, version) => window.backend.App.GetSnapshotVersion(id, version),
+  diffSnapshotVersions: (id, from, to) => window.backend.App.DiffSnapshotVersions(id, from, to),
+  rollbackSnapshot: (id, version, note) => window.backend.App.RollbackSnapshot(id, version, note || ''),
+
+  // Snapshot import & export ([]byte travels as base64)
+  exportSnapshots: async (ids) => {
+    const bundle = await window.backend.App.ExportSnapshots(ids || [])
+    const bytes = Uint8Array.from(atob(bundle), (c) => c.charCodeAt(0))
+    const url = URL.createObjectURL(new Blob([bytes], { type: 'application/zip' }))
+    const link = document.createElement('a')
+    link.href = url
+    link.download = `snapshots-${new Date().toISOString().slice(0, 10)}.zip`
+    link.click()
+    URL.revokeObjectURL(url)
+  },
+  // format: 'bundle', 'chrome_recorder' or 'script'; file is a File from an <input type="file">.
+  // overwrite saves bundled snapshots over the ones with the same ID instead of under a new ID.
+  importSnapshots: (format, file, overwrite) =>
+    new Promise((resolve, reject) => {
+      const reader = new FileReader()
+      reader.onload = () => resolve(reader.result.slice(reader.result.indexOf(',') + 1))
+      reader.onerror = () => reject(reader.error)
+      reader.readAsDataURL(file)
+    }).then((data) => window.backend.App.ImportSnapshots(format, data, !!overwrite)),
+  pushSnapshotsToDaemon: (ids, overwrite) => window.backend.App.PushSnapshotsToDaemon(ids || [], !!overwrite),
+  pullSnapshotsFromDaemon: (ids, overwrite) => window.backend.App.PullSnapshotsFromDaemon(ids || [], !!overwrite),
+
+  // Snapshot secrets
+  storePasswordAsSecret: (snapshotId, actionIndex, name) =>
+    window.backend.App.StorePasswordAsSecret(snapshotId, actionIndex, name),
+  keepRecordedPasswords: (snapshotId) => window.backend.App.KeepRecordedPasswords(snapshotId),
+  listSecrets: () => window.backend.App.ListSecrets(),
+  setSecret: (name, value) => window.backend.App.SetSecret(name, value),
+  deleteSecret: (name) => window.backend.App.DeleteSecret(name),
+
   // SMTP
   configureSMTP: (host, port, username, password, from, to) =>
     window.backend.App.ConfigureSMTP(host, port, username, password, from, to),
   getSMTPStatus: () => window.backend.App.GetSMTPStatus(),
   getSMTPConfig: () => window.backend.App.GetSMTPConfig(),
 
+  // Webhooks
+  saveWebhook: (webhook) => window.backend.App.SaveWebhook(webhook),
+  getWebhooks: () => window.backend.App.GetWebhooks(),
+  deleteWebhook: (name) => window.backend.App.DeleteWebhook(name),
+  testWebhook: (name) => window.backend.App.TestWebhook(name),
+
+  // Alert acknowledgement & silencing
+  acknowledgeAlert: (target) => window.backend.App.AcknowledgeAlert(target),
+  getIncidents: () => window.backend.App.GetIncidents(),
+  silenceAlerts: (target, minutes, reason) =>
+    window.backend.App.SilenceAlerts(target, minutes, reason),
+  removeSilence: (id) => window.backend.App.RemoveSilence(id),
+  getSilences: () => window.backend.App.GetSilences(),
+
+  // Maintenance windows
+  saveMaintenanceWindow: (maintenanceWindow) =>
+    window.backend.App.SaveMaintenanceWindow(maintenanceWindow),
+  getMaintenanceWindows: () => window.backend.App.GetMaintenanceWindows(),
+  deleteMaintenanceWindow: (id) => window.backend.App.DeleteMaintenanceWindow(id),
+
+  // Check history
+  getHistory: (target, from, to, resolution) =>
+    window.backend.App.GetHistory(target, from, to, resolution),
+
+  // HAR recordings
+  getHARRecordings: (target) => window.backend.App.GetHARRecordings(target || ''),
+  downloadHAR: async (id) => {
+    const recording = await window.backend.App.GetHAR(id)
+    const blob = new Blob([JSON.stringify(recording.content, null, 2)], { type: 'application/json' })
+    const url = URL.createObjectURL(blob)
+    const link = document.createElement('a')
+    link.href = url
+    link.download = recording.file_name
+    link.click()
+    URL.revokeObjectURL(url)
+  },
+
+  // Replay failure artifacts (screenshot + DOM of failed steps)
+  getReplayArtifacts: (target) => window.backend.App.GetReplayArtifacts(target || ''),
+  getReplayArtifact: (id) => window.backend.App.GetReplayArtifact(id),
+  artifactScreenshotURL: (data) => (data.screenshot ? `data:image/jpeg;base64,${data.screenshot}` : null),
+
   // Settings
   getAppSettings: () => window.backend.App.GetAppSettings(),
   saveAppSettings: (workerSleepTime, headlessBrowserMode) => window.backend.App.SaveAppSettings(workerSleepTime, headlessBrowserMode),
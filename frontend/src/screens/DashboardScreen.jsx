@@ -5,6 +5,114 @@ import { FontAwesomeIcon } from '@fortawesome/react-fontawesome'
 import { faSyncAlt, faExclamationTriangle, faTrash } from '@fortawesome/free-solid-svg-icons'
 import api from '../api'
 
+// HARRecordings lists the HAR recordings of a website with download links
+function HARRecordings({ url, setError }) {
+  const [recordings, setRecordings] = useState(null)
+
+  useEffect(() => {
+    api.getHARRecordings(url)
+      .then((list) => setRecordings(list || []))
+      .catch((err) => setError('Failed to load HAR recordings: ' + err))
+  }, [url])
+
+  if (recordings === null) {
+    return <p className="text-xs text-gray-500">Loading recordings...</p>
+  }
+  if (recordings.length === 0) {
+    return <p className="text-xs text-gray-500">No HAR recordings (set "har" on the target to record)</p>
+  }
+  return (
+    <ul className="space-y-1 text-xs">
+      {recordings.map((rec) => (
+        <li key={rec.id} className="flex justify-between items-center">
+          <span className={rec.success ? 'text-gray-700' : 'text-red-600'}>
+            {new Date(rec.created_at).toLocaleString()} · {rec.kind}
+            {rec.snapshot_id && ` ${rec.snapshot_id}`}
+            {rec.snapshot_version > 0 && ` (v${rec.snapshot_version})`} · {rec.entries} requests
+          </span>
+          <button
+            onClick={() => api.downloadHAR(rec.id).catch((err) => setError('Failed to download HAR: ' + err))}
+            className="text-blue-600 hover:underline"
+          >
+            Download
+          </button>
+        </li>
+      ))}
+    </ul>
+  )
+}
+
+// FailureArtifacts lists the screenshots and DOMs captured when replay steps of a
+// website failed, showing the screenshot of the selected one
+function FailureArtifacts({ url, setError }) {
+  const [artifacts, setArtifacts] = useState(null)
+  const [selected, setSelected] = useState(null) // GET_ARTIFACT data of the artifact shown
+
+  useEffect(() => {
+    api.getReplayArtifacts(url)
+      .then((list) => setArtifacts(list || []))
+      .catch((err) => setError('Failed to load failure artifacts: ' + err))
+  }, [url])
+
+  const handleView = (id) => {
+    if (selected && selected.info.id === id) {
+      setSelected(null)
+      return
+    }
+    api.getReplayArtifact(id)
+      .then(setSelected)
+      .catch((err) => setError('Failed to load artifact: ' + err))
+  }
+
+  const handleDownloadDOM = () => {
+    const blob = new Blob([selected.dom], { type: 'text/html' })
+    const link = document.createElement('a')
+    link.href = URL.createObjectURL(blob)
+    link.download = `${selected.info.id}.html`
+    link.click()
+    URL.revokeObjectURL(link.href)
+  }
+
+  if (artifacts === null) {
+    return <p className="text-xs text-gray-500">Loading artifacts...</p>
+  }
+  if (artifacts.length === 0) {
+    return <p className="text-xs text-gray-500">No failed replay steps captured</p>
+  }
+  return (
+    <ul className="space-y-1 text-xs">
+      {artifacts.map((info) => (
+        <li key={info.id}>
+          <div className="flex justify-between items-center">
+            <span className="text-red-600">
+              {new Date(info.created_at).toLocaleString()} · {info.snapshot_id}
+              {info.snapshot_version > 0 && ` (v${info.snapshot_version})`} ·{' '}
+              {info.action_index < 0 ? 'Initial navigation' : `Step ${info.action_index + 1} (${info.action_type})`}
+            </span>
+            <button onClick={() => handleView(info.id)} className="text-blue-600 hover:underline">
+              {selected && selected.info.id === info.id ? 'Hide' : 'View'}
+            </button>
+          </div>
+          {selected && selected.info.id === info.id && (
+            <div className="mt-1 space-y-1">
+              <p className="text-gray-700 break-all">{info.error}</p>
+              {info.page_url && <p className="text-gray-500 break-all">{info.page_url}</p>}
+              {api.artifactScreenshotURL(selected) && (
+                <img src={api.artifactScreenshotURL(selected)} alt="Page when the step failed" className="border border-gray-200 rounded" />
+              )}
+              {selected.dom && (
+                <button onClick={handleDownloadDOM} className="text-blue-600 hover:underline">
+                  Download DOM
+                </button>
+              )}
+            </div>
+          )}
+        </li>
+      ))}
+    </ul>
+  )
+}
+
 function DashboardScreen({ error, setError, onNavigate }) {
   const [dashboardData, setDashboardData] = useState(null)
   const [loading, setLoading] = useState(true)
@@ -20,6 +128,8 @@ function DashboardScreen({ error, setError, onNavigate }) {
   const [monitoredWebsites, setMonitoredWebsites] = useState([]) // URLs currently being monitored (for display after refresh)
   const [isMonitoringActive, setIsMonitoringActive] = useState(false)
   const [isStopping, setIsStopping] = useState(false)
+  const [expandedRecordings, setExpandedRecordings] = useState(null) // URL whose HAR recordings are shown
+  const [expandedArtifacts, setExpandedArtifacts] = useState(null) // URL whose failure artifacts are shown
 
   useEffect(() => {
     let isMounted = true
@@ -512,6 +622,36 @@ function DashboardScreen({ error, setError, onNavigate }) {
                           <span>{site.last_check_time || 'Never'}</span>
                         </div>
                       </div>
+
+                      {/* HAR Recordings */}
+                      <div className="mt-3 pt-3 border-t border-gray-100">
+                        <button
+                          onClick={() => setExpandedRecordings(expandedRecordings === site.url ? null : site.url)}
+                          className="text-sm text-blue-600 hover:underline"
+                        >
+                          {expandedRecordings === site.url ? 'Hide HAR recordings' : 'HAR recordings'}
+                        </button>
+                        {expandedRecordings === site.url && (
+                          <div className="mt-2">
+                            <HARRecordings url={site.url} setError={setError} />
+                          </div>
+                        )}
+                      </div>
+
+                      {/* Failure Artifacts */}
+                      <div className="mt-2">
+                        <button
+                          onClick={() => setExpandedArtifacts(expandedArtifacts === site.url ? null : site.url)}
+                          className="text-sm text-blue-600 hover:underline"
+                        >
+                          {expandedArtifacts === site.url ? 'Hide failed steps' : 'Failed steps'}
+                        </button>
+                        {expandedArtifacts === site.url && (
+                          <div className="mt-2">
+                            <FailureArtifacts url={site.url} setError={setError} />
+                          </div>
+                        )}
+                      </div>
                     </div>
                   ))}
                 </div>
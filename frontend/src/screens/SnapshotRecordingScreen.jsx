@@ -2,6 +2,23 @@ import React, { useState, useEffect } from 'react'
 import api from '../api'
 import { normalizeUrl } from '../utils/urlUtils'
 
+// Assertion types that can be inserted while recording, with the fields each one uses
+const ASSERTION_TYPES = [
+  { type: 'assert_visible', label: 'Element is visible', fields: ['selector'] },
+  { type: 'assert_exists', label: 'Element exists', fields: ['selector'] },
+  { type: 'assert_text', label: 'Text contains', fields: ['selector', 'value'] },
+  { type: 'assert_count', label: 'Element count', fields: ['selector', 'value'] },
+  { type: 'assert_url', label: 'URL matches', fields: ['pattern'] },
+  { type: 'assert_api', label: 'API call observed', fields: ['pattern', 'status'] },
+]
+
+const FIELD_PLACEHOLDERS = {
+  selector: 'CSS selector, e.g. #checkout-done',
+  value: 'Expected text, or a count such as >=1',
+  pattern: 'URL regex, e.g. /api/orders$',
+  status: 'Status (empty = any below 400)',
+}
+
 function SnapshotRecordingScreen({ onBack, url }) {
   const [recording, setRecording] = useState(false)
   const [progress, setProgress] = useState('')
@@ -9,6 +26,12 @@ function SnapshotRecordingScreen({ onBack, url }) {
   const [success, setSuccess] = useState('')
   const [normalizedUrl, setNormalizedUrl] = useState('')
   const [recordingId, setRecordingId] = useState(null)
+  const [assertion, setAssertion] = useState({ type: 'assert_visible', selector: '', value: '', pattern: '', status: '' })
+  const [assertionMessage, setAssertionMessage] = useState('')
+  const [savedSnapshot, setSavedSnapshot] = useState(null)
+  const [passwordInputs, setPasswordInputs] = useState([])
+  const [secretNames, setSecretNames] = useState({})
+  const [secretMessage, setSecretMessage] = useState('')
 
   useEffect(() => {
     if (url) {
@@ -51,7 +74,10 @@ function SnapshotRecordingScreen({ onBack, url }) {
 
     try {
       setProgress('Saving snapshot...')
-      await api.finishRecording(recordingId)
+      const result = await api.finishRecording(recordingId)
+      setSavedSnapshot(result.snapshot)
+      setPasswordInputs(result.password_inputs || [])
+      setSecretNames(Object.fromEntries((result.password_inputs || []).map((p) => [p.action_index, p.suggested_name])))
       setSuccess('Snapshot recorded and saved successfully!')
       setProgress('')
       setRecordingId(null)
@@ -63,6 +89,48 @@ function SnapshotRecordingScreen({ onBack, url }) {
     }
   }
 
+  const handleAddAssertion = async () => {
+    const action = {
+      type: assertion.type,
+      selector: assertion.selector.trim(),
+      value: assertion.value,
+      pattern: assertion.pattern.trim(),
+      status: parseInt(assertion.status, 10) || 0,
+    }
+    try {
+      await api.addRecordingAssertion(recordingId, action)
+      setAssertionMessage('Assertion added')
+      setAssertion({ ...assertion, selector: '', value: '', pattern: '', status: '' })
+    } catch (err) {
+      setAssertionMessage(`Failed to add assertion: ${err.message || err}`)
+    }
+  }
+
+  // Storing replaces the input's run of keystrokes with a single action, which shifts
+  // the indexes of later inputs, so the last one is stored first
+  const handleStorePassword = async (input) => {
+    const name = (secretNames[input.action_index] || '').trim()
+    try {
+      await api.storePasswordAsSecret(savedSnapshot.id, input.action_index, name)
+      setPasswordInputs(passwordInputs.filter((p) => p.action_index !== input.action_index))
+      setSecretMessage(`Password stored as {{secret.${name}}}`)
+    } catch (err) {
+      setSecretMessage(`Failed to store secret: ${err.message || err}`)
+    }
+  }
+
+  const handleKeepPasswords = async () => {
+    try {
+      await api.keepRecordedPasswords(savedSnapshot.id)
+      setPasswordInputs([])
+      setSecretMessage('Passwords saved in the snapshot')
+    } catch (err) {
+      setSecretMessage(`Failed to keep passwords: ${err.message || err}`)
+    }
+  }
+
+  const assertionFields = ASSERTION_TYPES.find((t) => t.type === assertion.type).fields
+
   return (
     <div className="min-h-screen bg-gray-50">
       <div className="bg-white border-b border-gray-200">
@@ -101,6 +169,41 @@ function SnapshotRecordingScreen({ onBack, url }) {
             </div>
           )}
 
+          {recordingId && (
+            <div className="p-4 border border-gray-200 rounded-lg mb-4">
+              <h2 className="text-sm font-semibold text-gray-900">Add Assertion</h2>
+              <p className="text-xs text-gray-600 mt-1">
+                Verified at this point of the replay. Tip: Alt+click an element in the browser to assert its text.
+              </p>
+              <select
+                value={assertion.type}
+                onChange={(e) => setAssertion({ ...assertion, type: e.target.value })}
+                className="mt-3 w-full px-3 py-2 border border-gray-300 rounded-lg text-sm"
+              >
+                {ASSERTION_TYPES.map((t) => (
+                  <option key={t.type} value={t.type}>{t.label}</option>
+                ))}
+              </select>
+              {assertionFields.map((field) => (
+                <input
+                  key={field}
+                  type="text"
+                  value={assertion[field]}
+                  placeholder={FIELD_PLACEHOLDERS[field]}
+                  onChange={(e) => setAssertion({ ...assertion, [field]: e.target.value })}
+                  className="mt-2 w-full px-3 py-2 border border-gray-300 rounded-lg text-sm"
+                />
+              ))}
+              <button
+                onClick={handleAddAssertion}
+                className="mt-3 w-full px-4 py-2 bg-blue-600 hover:bg-blue-700 text-white rounded-lg font-semibold"
+              >
+                Add Assertion
+              </button>
+              {assertionMessage && <p className="text-xs text-gray-700 mt-2">{assertionMessage}</p>}
+            </div>
+          )}
+
           {error && (
             <div className="p-4 bg-red-50 border border-red-200 rounded-lg mb-4">
               <p className="text-sm text-red-800">{error}</p>
@@ -125,6 +228,44 @@ function SnapshotRecordingScreen({ onBack, url }) {
             </div>
           )}
 
+          {passwordInputs.length > 0 && (
+            <div className="p-4 bg-yellow-50 border border-yellow-200 rounded-lg mb-4">
+              <h2 className="text-sm font-semibold text-gray-900">Passwords left out of the snapshot</h2>
+              <p className="text-xs text-gray-600 mt-1">
+                Store them in the encrypted secret store so the snapshot only references them, or keep them in the
+                snapshot in clear. Until then the snapshot types "[redacted]" into these fields.
+              </p>
+              {passwordInputs.map((input, i) => (
+                <div key={input.action_index} className="mt-3">
+                  <p className="text-xs text-gray-700 font-mono">{input.selector}</p>
+                  <div className="flex gap-2 mt-1">
+                    <input
+                      type="text"
+                      value={secretNames[input.action_index] || ''}
+                      onChange={(e) => setSecretNames({ ...secretNames, [input.action_index]: e.target.value })}
+                      className="flex-1 px-3 py-2 border border-gray-300 rounded-lg text-sm"
+                    />
+                    <button
+                      onClick={() => handleStorePassword(input)}
+                      disabled={i !== passwordInputs.length - 1}
+                      className="px-4 py-2 bg-yellow-600 hover:bg-yellow-700 disabled:bg-gray-400 text-white rounded-lg font-semibold text-sm"
+                    >
+                      Store as Secret
+                    </button>
+                  </div>
+                </div>
+              ))}
+              <button
+                onClick={handleKeepPasswords}
+                className="mt-3 text-xs text-gray-600 hover:text-gray-900 underline"
+              >
+                Keep the remaining passwords in the snapshot
+              </button>
+            </div>
+          )}
+
+          {secretMessage && <p className="text-xs text-gray-700 mb-4">{secretMessage}</p>}
+
           {!success && !error && !progress && !recording && (
             <button
               onClick={() => handleStartRecording()}
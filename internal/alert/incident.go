@@ -0,0 +1,244 @@
+package alert
+
+import (
+	"apiwatcher/internal/config"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ==========================
+// Incidents
+// ==========================
+
+// Incident tracks an ongoing failure for a single alert key
+type Incident struct {
+	Key         string    `json:"key"`
+	OpenedAt    time.Time `json:"opened_at"`     // First failure of the incident
+	LastAlertAt time.Time `json:"last_alert_at"` // Zero until the first alert is sent
+	AlertCount  int       `json:"alert_count"`
+	Escalated   bool      `json:"escalated"`
+
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitzero"` // Stops repeats and escalation
+	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
+}
+
+// Acknowledged reports whether someone has taken ownership of the incident
+func (i *Incident) Acknowledged() bool {
+	return !i.AcknowledgedAt.IsZero()
+}
+
+// Incidents maps alert keys to their open incident
+type Incidents map[string]*Incident
+
+// Decision is the outcome of evaluating an alert policy for a failure
+type Decision struct {
+	Notify   bool   // Send to the regular channels
+	Repeat   bool   // The notification repeats an earlier alert for the same incident
+	Escalate bool   // Also send to the escalation recipients
+	Reason   string // Why nothing is sent (when Notify and Escalate are false)
+}
+
+// IncidentStore persists the open incidents to a JSON file. Each operation is a
+// load-modify-save cycle of the file. A nil store tracks no incidents.
+type IncidentStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// errNoIncidents is returned by the operations of a nil store
+var errNoIncidents = fmt.Errorf("incidents are not tracked")
+
+// NewIncidentStore returns a store keeping incidents at path
+func NewIncidentStore(path string) *IncidentStore {
+	return &IncidentStore{path: path}
+}
+
+// ==========================
+// Incident Persistence
+// ==========================
+func (s *IncidentStore) load() (Incidents, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(Incidents), nil
+		}
+		return nil, err
+	}
+	var incidents Incidents
+	if err := json.Unmarshal(data, &incidents); err != nil {
+		return nil, err
+	}
+	if incidents == nil {
+		incidents = make(Incidents)
+	}
+	return incidents, nil
+}
+
+func (s *IncidentStore) save(incidents Incidents) error {
+	os.MkdirAll(filepath.Dir(s.path), 0755)
+	data, err := json.MarshalIndent(incidents, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// ==========================
+// Policy Evaluation
+// ==========================
+
+// Evaluate opens (or continues) the incident for key and decides whether this
+// failure should be alerted. failures is the number of consecutive failures
+// including the current one; lastAlert is when an alert for key was last sent.
+func (s *IncidentStore) Evaluate(key string, policy *config.AlertPolicy, failures int, lastAlert time.Time, now time.Time) (Decision, error) {
+	if s == nil {
+		return Decision{}, errNoIncidents
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incidents, err := s.load()
+	if err != nil {
+		return Decision{}, err
+	}
+
+	incident, exists := incidents[key]
+	if !exists {
+		incident = &Incident{Key: key, OpenedAt: now}
+		incidents[key] = incident
+		if err := s.save(incidents); err != nil {
+			return Decision{}, err
+		}
+	}
+
+	return decide(incident, policy, failures, lastAlert, now), nil
+}
+
+// decide applies a policy to an open incident
+func decide(incident *Incident, policy *config.AlertPolicy, failures int, lastAlert time.Time, now time.Time) Decision {
+	var d Decision
+
+	// Escalation waits for the threshold too, a single blip doesn't page anyone
+	if escalateAfter := policy.EscalateAfter(); escalateAfter > 0 && failures >= policy.Threshold() &&
+		!incident.Escalated && !incident.Acknowledged() && now.Sub(incident.OpenedAt) >= escalateAfter {
+		d.Escalate = true
+	}
+
+	switch {
+	case failures < policy.Threshold():
+		d.Reason = "below failure threshold"
+	case incident.LastAlertAt.IsZero():
+		// First alert of this incident, unless an earlier incident alerted recently
+		if !lastAlert.IsZero() && now.Sub(lastAlert) < policy.Cooldown() {
+			d.Reason = "sent recently"
+		} else {
+			d.Notify = true
+		}
+	case incident.Acknowledged():
+		d.Reason = "acknowledged"
+	case now.Sub(incident.LastAlertAt) >= policy.RepeatInterval():
+		d.Notify = true
+		d.Repeat = true
+	default:
+		d.Reason = "sent recently"
+	}
+
+	return d
+}
+
+// RecordSent records that an alert was delivered for the incident of key
+func (s *IncidentStore) RecordSent(key string, d Decision, now time.Time) error {
+	if s == nil {
+		return errNoIncidents
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incidents, err := s.load()
+	if err != nil {
+		return err
+	}
+	incident, exists := incidents[key]
+	if !exists {
+		incident = &Incident{Key: key, OpenedAt: now}
+		incidents[key] = incident
+	}
+
+	if d.Notify {
+		incident.LastAlertAt = now
+		incident.AlertCount++
+	}
+	if d.Escalate {
+		incident.Escalated = true
+	}
+
+	return s.save(incidents)
+}
+
+// Acknowledge marks the open incident for key as acknowledged
+func (s *IncidentStore) Acknowledge(key string, by string) (*Incident, error) {
+	if s == nil {
+		return nil, errNoIncidents
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incidents, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	incident, exists := incidents[key]
+	if !exists {
+		return nil, fmt.Errorf("no open incident for %s", key)
+	}
+	if !incident.Acknowledged() {
+		incident.AcknowledgedAt = time.Now()
+		incident.AcknowledgedBy = by
+	}
+	return incident, s.save(incidents)
+}
+
+// List returns all open incidents
+func (s *IncidentStore) List() ([]Incident, error) {
+	if s == nil {
+		return []Incident{}, nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incidents, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Incident, 0, len(incidents))
+	for _, incident := range incidents {
+		list = append(list, *incident)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].OpenedAt.Before(list[j].OpenedAt) })
+	return list, nil
+}
+
+// Resolve closes the incident for key, returning it if one was open
+func (s *IncidentStore) Resolve(key string) (*Incident, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incidents, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	incident, exists := incidents[key]
+	if !exists {
+		return nil, nil
+	}
+	delete(incidents, key)
+	return incident, s.save(incidents)
+}
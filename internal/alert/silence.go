@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SilenceAll is the silence target that matches every website
+const SilenceAll = "*"
+
+// ==========================
+// Silences
+// ==========================
+
+// Silence suppresses alerts for a target until it expires
+type Silence struct {
+	ID        string    `json:"id"`
+	Target    string    `json:"target"` // Website URL or SilenceAll
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Matches reports whether the silence covers a website at the given time
+func (s *Silence) Matches(website string, now time.Time) bool {
+	if !now.Before(s.ExpiresAt) {
+		return false
+	}
+	return s.Target == SilenceAll || s.Target == website
+}
+
+// SilenceStore holds silences persisted to a JSON file
+type SilenceStore struct {
+	path     string
+	silences []*Silence
+	mutex    sync.RWMutex
+}
+
+// NewSilenceStore loads the silences stored at path
+func NewSilenceStore(path string) (*SilenceStore, error) {
+	store := &SilenceStore{path: path, silences: []*Silence{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read silences: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.silences); err != nil {
+		return store, fmt.Errorf("failed to unmarshal silences: %w", err)
+	}
+	return store, nil
+}
+
+// Add creates a silence for target lasting duration
+func (s *SilenceStore) Add(target string, duration time.Duration, reason string) (*Silence, error) {
+	if target == "" {
+		return nil, fmt.Errorf("silence target is required")
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("silence duration must be positive")
+	}
+
+	now := time.Now()
+	silence := &Silence{
+		ID:        newSilenceID(),
+		Target:    target,
+		Reason:    reason,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pruneLocked(now)
+	s.silences = append(s.silences, silence)
+	return silence, s.saveLocked()
+}
+
+// Remove deletes a silence by ID
+func (s *SilenceStore) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, silence := range s.silences {
+		if silence.ID == id {
+			s.silences = append(s.silences[:i], s.silences[i+1:]...)
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("silence not found: %s", id)
+}
+
+// List returns the silences that haven't expired yet
+func (s *SilenceStore) List() []Silence {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	active := make([]Silence, 0, len(s.silences))
+	for _, silence := range s.silences {
+		if now.Before(silence.ExpiresAt) {
+			active = append(active, *silence)
+		}
+	}
+	return active
+}
+
+// Active returns the silence covering a website, or nil if alerts may be sent.
+// A nil store never silences anything.
+func (s *SilenceStore) Active(website string) *Silence {
+	if s == nil {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	for _, silence := range s.silences {
+		if silence.Matches(website, now) {
+			found := *silence
+			return &found
+		}
+	}
+	return nil
+}
+
+// pruneLocked drops expired silences. Caller must hold the write lock.
+func (s *SilenceStore) pruneLocked(now time.Time) {
+	active := s.silences[:0]
+	for _, silence := range s.silences {
+		if now.Before(silence.ExpiresAt) {
+			active = append(active, silence)
+		}
+	}
+	s.silences = active
+}
+
+// saveLocked writes the silences to disk. Caller must hold the write lock.
+func (s *SilenceStore) saveLocked() error {
+	data, err := json.MarshalIndent(s.silences, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal silences: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write silences: %w", err)
+	}
+	return nil
+}
+
+func newSilenceID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
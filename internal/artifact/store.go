@@ -0,0 +1,188 @@
+package artifact
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxPerTarget is how many failure artifacts are kept per website; older ones are deleted
+const maxPerTarget = 50
+
+const indexFile = "index.json"
+
+// Info describes the page state captured when a snapshot replay step failed
+type Info struct {
+	ID             string    `json:"id"`
+	Target         string    `json:"target"`
+	SnapshotID     string    `json:"snapshot_id"`
+	Version        int       `json:"snapshot_version,omitempty"` // Snapshot version that failed
+	ActionIndex    int       `json:"action_index"`               // Failed action (-1 = initial navigation)
+	ActionType     string    `json:"action_type"`
+	Selector       string    `json:"selector,omitempty"`
+	Error          string    `json:"error"`
+	PageURL        string    `json:"page_url,omitempty"` // Page URL when the step failed
+	CreatedAt      time.Time `json:"created_at"`
+	ScreenshotSize int64     `json:"screenshot_size"` // Bytes (0 if the screenshot failed)
+	DOMSize        int64     `json:"dom_size"`        // Bytes (0 if the DOM couldn't be read)
+}
+
+// Store keeps failure screenshots (JPEG) and DOM dumps (HTML) in a directory with a JSON index
+type Store struct {
+	dir   string
+	index []*Info
+	mutex sync.RWMutex
+}
+
+// NewID returns a random artifact ID. IDs are assigned when the failure is
+// captured so alerts can reference the artifact before it is stored.
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// OpenStore opens (creating if needed) an artifact store rooted at dir
+func OpenStore(dir string) (*Store, error) {
+	// Screenshots and DOMs show whatever the page showed, logged in pages included
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	store := &Store{dir: dir, index: []*Info{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read artifact index: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.index); err != nil {
+		return store, fmt.Errorf("failed to unmarshal artifact index: %w", err)
+	}
+	return store, nil
+}
+
+// Save stores an artifact and deletes the target's oldest ones beyond the limit.
+// A new ID is assigned if info has none.
+func (s *Store) Save(info Info, screenshot []byte, dom string) (*Info, error) {
+	if info.ID == "" {
+		info.ID = NewID()
+	}
+	if info.CreatedAt.IsZero() {
+		info.CreatedAt = time.Now()
+	}
+	info.ScreenshotSize = int64(len(screenshot))
+	info.DOMSize = int64(len(dom))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(screenshot) > 0 {
+		if err := os.WriteFile(s.screenshotPath(info.ID), screenshot, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write screenshot: %w", err)
+		}
+	}
+	if dom != "" {
+		if err := os.WriteFile(s.domPath(info.ID), []byte(dom), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write DOM: %w", err)
+		}
+	}
+	s.index = append(s.index, &info)
+	s.pruneLocked(info.Target)
+	return &info, s.saveLocked()
+}
+
+// List returns the artifacts of a target (every target if empty), newest first
+func (s *Store) List(target string) []Info {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := []Info{}
+	for _, info := range s.index {
+		if target == "" || info.Target == target {
+			list = append(list, *info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Read returns an artifact with its screenshot and DOM
+func (s *Store) Read(id string) (*Info, []byte, string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, info := range s.index {
+		if info.ID != id {
+			continue
+		}
+		var screenshot, dom []byte
+		var err error
+		if info.ScreenshotSize > 0 {
+			if screenshot, err = os.ReadFile(s.screenshotPath(id)); err != nil {
+				return nil, nil, "", fmt.Errorf("failed to read screenshot: %w", err)
+			}
+		}
+		if info.DOMSize > 0 {
+			if dom, err = os.ReadFile(s.domPath(id)); err != nil {
+				return nil, nil, "", fmt.Errorf("failed to read DOM: %w", err)
+			}
+		}
+		found := *info
+		return &found, screenshot, string(dom), nil
+	}
+	return nil, nil, "", fmt.Errorf("artifact not found: %s", id)
+}
+
+// pruneLocked deletes a target's oldest artifacts beyond maxPerTarget. Caller must hold the write lock.
+func (s *Store) pruneLocked(target string) {
+	sort.SliceStable(s.index, func(i, j int) bool { return s.index[i].CreatedAt.Before(s.index[j].CreatedAt) })
+
+	count := 0
+	for _, info := range s.index {
+		if info.Target == target {
+			count++
+		}
+	}
+
+	kept := s.index[:0]
+	for _, info := range s.index {
+		if info.Target == target && count > maxPerTarget {
+			os.Remove(s.screenshotPath(info.ID))
+			os.Remove(s.domPath(info.ID))
+			count--
+			continue
+		}
+		kept = append(kept, info)
+	}
+	s.index = kept
+}
+
+// saveLocked writes the index to disk. Caller must hold the write lock.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, indexFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write artifact index: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) screenshotPath(id string) string {
+	return filepath.Join(s.dir, id+".jpg")
+}
+
+func (s *Store) domPath(id string) string {
+	return filepath.Join(s.dir, id+".html")
+}
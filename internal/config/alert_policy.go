@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultAlertCooldown is the suppression window used when a policy doesn't set one
+const DefaultAlertCooldown = 5 * time.Hour
+
+// AlertPolicy controls when alerts for a target are sent, repeated and escalated.
+// A nil policy behaves like the zero policy: alert on the first failure, then at
+// most once every DefaultAlertCooldown.
+type AlertPolicy struct {
+	CooldownMinutes       int      `json:"cooldown_minutes,omitempty"`        // Minimum gap before alerting for a new incident (0 = 5 hours)
+	FailureThreshold      int      `json:"failure_threshold,omitempty"`       // Consecutive failures before the first alert (0 = 1)
+	RepeatIntervalMinutes int      `json:"repeat_interval_minutes,omitempty"` // Re-alert while still failing (0 = cooldown)
+	EscalateAfterMinutes  int      `json:"escalate_after_minutes,omitempty"`  // Escalate unacknowledged incidents after N minutes (0 = never)
+	EscalationEmails      []string `json:"escalation_emails,omitempty"`       // Recipients of escalated alerts
+}
+
+// Cooldown returns the minimum gap before alerting for a new incident
+func (p *AlertPolicy) Cooldown() time.Duration {
+	if p == nil || p.CooldownMinutes <= 0 {
+		return DefaultAlertCooldown
+	}
+	return time.Duration(p.CooldownMinutes) * time.Minute
+}
+
+// Threshold returns the number of consecutive failures needed before alerting
+func (p *AlertPolicy) Threshold() int {
+	if p == nil || p.FailureThreshold <= 0 {
+		return 1
+	}
+	return p.FailureThreshold
+}
+
+// RepeatInterval returns how often to re-alert during an ongoing incident
+func (p *AlertPolicy) RepeatInterval() time.Duration {
+	if p == nil || p.RepeatIntervalMinutes <= 0 {
+		return p.Cooldown()
+	}
+	return time.Duration(p.RepeatIntervalMinutes) * time.Minute
+}
+
+// EscalateAfter returns how long an incident may stay unacknowledged before
+// escalating, or 0 if the policy never escalates
+func (p *AlertPolicy) EscalateAfter() time.Duration {
+	if p == nil || p.EscalateAfterMinutes <= 0 || len(p.EscalationEmails) == 0 {
+		return 0
+	}
+	return time.Duration(p.EscalateAfterMinutes) * time.Minute
+}
+
+// ValidateAlertPolicy validates alert policy fields
+func ValidateAlertPolicy(p *AlertPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.CooldownMinutes < 0 || p.RepeatIntervalMinutes < 0 || p.EscalateAfterMinutes < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if p.FailureThreshold < 0 {
+		return fmt.Errorf("failure threshold must not be negative")
+	}
+	if p.EscalateAfterMinutes > 0 && len(p.EscalationEmails) == 0 {
+		return fmt.Errorf("escalation requires at least one escalation email")
+	}
+	for _, email := range p.EscalationEmails {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("invalid escalation email address: %q", email)
+		}
+	}
+	return nil
+}
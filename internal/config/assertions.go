@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Assertion types supported for API checks
+const (
+	AssertJSONPath    = "jsonpath"     // Value at Path must equal Expected
+	AssertBodyRegex   = "body_regex"   // Body must match the Expected regex
+	AssertHeader      = "header"       // Header must be present (and equal Expected if set)
+	AssertContentType = "content_type" // Content-Type must start with Expected
+	AssertMaxLatency  = "max_latency"  // Response must arrive within MaxLatencyMs
+)
+
+// Assertion is a single response check for a target or for captured requests.
+// With an empty URLPattern it applies to the target itself (the probed URL or the
+// main document); otherwise it applies to every captured request whose URL matches.
+type Assertion struct {
+	Type         string `json:"type"`
+	URLPattern   string `json:"url_pattern,omitempty"`    // Regex on captured request URLs
+	Path         string `json:"path,omitempty"`           // JSONPath, e.g. $.data.items[0].status
+	Header       string `json:"header,omitempty"`         // Header name for header assertions
+	Expected     string `json:"expected,omitempty"`       // Expected value, regex or content type
+	MaxLatencyMs int    `json:"max_latency_ms,omitempty"` // Limit for max_latency assertions
+
+	urlPattern  *regexp.Regexp // Compiled URLPattern
+	bodyPattern *regexp.Regexp // Compiled Expected of body_regex assertions
+}
+
+// UnmarshalJSON decodes an assertion and compiles its patterns once, so checks don't
+// compile them for every response. Invalid patterns are left to ValidateAssertion.
+func (a *Assertion) UnmarshalJSON(data []byte) error {
+	type rawAssertion Assertion
+	var raw rawAssertion
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = Assertion(raw)
+	if a.URLPattern != "" {
+		a.urlPattern, _ = regexp.Compile(a.URLPattern)
+	}
+	if a.Type == AssertBodyRegex {
+		a.bodyPattern, _ = regexp.Compile(a.Expected)
+	}
+	return nil
+}
+
+// Describe returns a short human readable description of the assertion
+func (a Assertion) Describe() string {
+	var desc string
+	switch a.Type {
+	case AssertJSONPath:
+		desc = fmt.Sprintf("%s == %q", a.Path, a.Expected)
+	case AssertBodyRegex:
+		desc = fmt.Sprintf("body matches /%s/", a.Expected)
+	case AssertHeader:
+		if a.Expected != "" {
+			desc = fmt.Sprintf("header %s == %q", a.Header, a.Expected)
+		} else {
+			desc = fmt.Sprintf("header %s present", a.Header)
+		}
+	case AssertContentType:
+		desc = fmt.Sprintf("content-type %s", a.Expected)
+	case AssertMaxLatency:
+		desc = fmt.Sprintf("latency <= %dms", a.MaxLatencyMs)
+	default:
+		desc = a.Type
+	}
+	if a.URLPattern != "" {
+		desc += fmt.Sprintf(" (requests matching /%s/)", a.URLPattern)
+	}
+	return desc
+}
+
+// Matches reports whether the assertion applies to a response.
+// isTarget is true for the target's own response (probe or main document).
+func (a Assertion) Matches(url string, isTarget bool) bool {
+	if a.URLPattern == "" {
+		return isTarget
+	}
+	re := a.urlPattern
+	if re == nil {
+		// Not decoded from JSON (or invalid)
+		var err error
+		if re, err = regexp.Compile(a.URLPattern); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(url)
+}
+
+// BodyPattern returns the compiled pattern of a body_regex assertion
+func (a Assertion) BodyPattern() (*regexp.Regexp, error) {
+	if a.bodyPattern != nil {
+		return a.bodyPattern, nil
+	}
+	return regexp.Compile(a.Expected)
+}
+
+// ValidateAssertion validates assertion fields
+func ValidateAssertion(a Assertion) error {
+	if a.URLPattern != "" {
+		if _, err := regexp.Compile(a.URLPattern); err != nil {
+			return fmt.Errorf("invalid url_pattern: %w", err)
+		}
+	}
+
+	switch a.Type {
+	case AssertJSONPath:
+		if !strings.HasPrefix(strings.TrimSpace(a.Path), "$") {
+			return fmt.Errorf("jsonpath assertion requires a path starting with $")
+		}
+	case AssertBodyRegex:
+		if a.Expected == "" {
+			return fmt.Errorf("body_regex assertion requires an expected pattern")
+		}
+		if _, err := regexp.Compile(a.Expected); err != nil {
+			return fmt.Errorf("invalid body_regex pattern: %w", err)
+		}
+	case AssertHeader:
+		if strings.TrimSpace(a.Header) == "" {
+			return fmt.Errorf("header assertion requires a header name")
+		}
+	case AssertContentType:
+		if strings.TrimSpace(a.Expected) == "" {
+			return fmt.Errorf("content_type assertion requires an expected type")
+		}
+	case AssertMaxLatency:
+		if a.MaxLatencyMs <= 0 {
+			return fmt.Errorf("max_latency assertion requires max_latency_ms > 0")
+		}
+	default:
+		return fmt.Errorf("unknown assertion type: %q", a.Type)
+	}
+	return nil
+}
+
+// NeedsBody reports whether any of the assertions inspect the response body
+func NeedsBody(assertions []Assertion) bool {
+	for _, a := range assertions {
+		if a.Type == AssertJSONPath || a.Type == AssertBodyRegex {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"apiwatcher/internal/schedule"
+
 	"github.com/joho/godotenv"
 )
 
@@ -44,14 +46,77 @@ func init() {
 // Data Structures
 // ==========================
 type Config struct {
+	Version  int      `json:"version"`
 	Email    string   `json:"email"`
-	Websites []string `json:"websites"`
+	Websites []Target `json:"websites"`
+
+	// Version 1 per-URL option maps, folded into Websites on load
+	HTTPProbes map[string]*HTTPProbe         `json:"http_probes,omitempty"`
+	Assertions map[string][]Assertion        `json:"assertions,omitempty"`
+	Schedules  map[string]*schedule.Schedule `json:"schedules,omitempty"`
+}
+
+// UnmarshalJSON decodes a config and migrates older formats to CurrentConfigVersion
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type rawConfig Config
+	var raw rawConfig
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Config(raw)
+	c.Migrate()
+	return nil
+}
+
+// Migrate upgrades a config to CurrentConfigVersion by folding the version 1
+// per-URL option maps into the structured targets
+func (c *Config) Migrate() {
+	if c.Version >= CurrentConfigVersion {
+		return
+	}
+
+	for i := range c.Websites {
+		t := &c.Websites[i]
+		if probe, ok := c.HTTPProbes[t.URL]; ok && probe != nil && t.HTTPProbe == nil {
+			t.HTTPProbe = probe
+			t.CheckType = CheckModeHTTP
+		}
+		if assertions, ok := c.Assertions[t.URL]; ok && len(t.Assertions) == 0 {
+			t.Assertions = assertions
+		}
+		if sched, ok := c.Schedules[t.URL]; ok && t.Schedule == nil {
+			t.Schedule = sched
+		}
+	}
+
+	c.HTTPProbes = nil
+	c.Assertions = nil
+	c.Schedules = nil
+	c.Version = CurrentConfigVersion
+}
+
+// URLs returns the URLs of all configured targets
+func (c *Config) URLs() []string {
+	return TargetURLs(c.Websites)
+}
+
+// Target returns the configured target for a URL, or nil if it isn't monitored
+func (c *Config) Target(url string) *Target {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Websites {
+		if c.Websites[i].URL == url {
+			return &c.Websites[i]
+		}
+	}
+	return nil
 }
 
 type SavedMonitorConfig struct {
 	Name        string            `json:"name"` // User-friendly name
 	Email       string            `json:"email"`
-	Websites    []string          `json:"websites"`
+	Websites    []Target          `json:"websites"`     // Bare URL strings from older files are accepted
 	SnapshotIDs map[string]string `json:"snapshot_ids"` // URL -> Snapshot ID mapping
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
@@ -108,8 +173,9 @@ func PromptUser() *Config {
 	email = strings.TrimSpace(email)
 
 	cfg := &Config{
+		Version:  CurrentConfigVersion,
 		Email:    email,
-		Websites: websites,
+		Websites: TargetsFromURLs(websites),
 	}
 	Save(cfg)
 	fmt.Println("✅ Configuration saved to", path())
@@ -126,7 +192,7 @@ func savedConfigsPath() string {
 }
 
 // SaveMonitorConfig saves a complete monitoring configuration
-func SaveMonitorConfig(name, email string, websites []string, snapshotIDs map[string]string) error {
+func SaveMonitorConfig(name, email string, websites []Target, snapshotIDs map[string]string) error {
 	dir := savedConfigsPath()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -216,7 +282,7 @@ func PromptSelectSavedConfig() (*SavedMonitorConfig, error) {
 		fmt.Printf("%d [%s]\n", i+1, cfg.Name)
 		fmt.Printf("   Email: %s | Sites: %s | Snapshots: %d\n",
 			cfg.Email,
-			strings.Join(cfg.Websites, ", "),
+			strings.Join(TargetURLs(cfg.Websites), ", "),
 			snapshotCount)
 
 		fmt.Printf("   Created: %s\n", cfg.CreatedAt.Format("2006-01-02 15:04:05"))
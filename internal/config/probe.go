@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Check modes a website can be monitored with
+const (
+	CheckModeBrowser = "browser" // Full chromedp page load (default)
+	CheckModeHTTP    = "http"    // Lightweight pure Go HTTP probe
+)
+
+// DefaultProbeTimeout is used when an HTTP probe has no timeout configured
+const DefaultProbeTimeout = 10 * time.Second
+
+// HTTPProbe configures a lightweight HTTP check for a single target
+type HTTPProbe struct {
+	Method         string            `json:"method,omitempty"`          // Defaults to GET
+	Headers        map[string]string `json:"headers,omitempty"`         // Extra request headers
+	Body           string            `json:"body,omitempty"`            // Request body (for POST/PUT etc.)
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"` // Defaults to DefaultProbeTimeout
+	ExpectedStatus []string          `json:"expected_status,omitempty"` // e.g. "200", "200-299", "2xx" (defaults to 200-399)
+}
+
+// GetMethod returns the HTTP method to use, defaulting to GET
+func (p *HTTPProbe) GetMethod() string {
+	if p == nil || strings.TrimSpace(p.Method) == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(strings.TrimSpace(p.Method))
+}
+
+// GetTimeout returns the probe timeout, defaulting to DefaultProbeTimeout
+func (p *HTTPProbe) GetTimeout() time.Duration {
+	if p == nil || p.TimeoutSeconds <= 0 {
+		return DefaultProbeTimeout
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// IsExpectedStatus reports whether a status code matches the configured ranges
+func (p *HTTPProbe) IsExpectedStatus(status int) bool {
+	if p == nil || len(p.ExpectedStatus) == 0 {
+		return status >= 200 && status < 400
+	}
+	for _, spec := range p.ExpectedStatus {
+		low, high, err := parseStatusRange(spec)
+		if err != nil {
+			continue
+		}
+		if status >= low && status <= high {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateHTTPProbe validates HTTP probe fields
+func ValidateHTTPProbe(p *HTTPProbe) error {
+	if p == nil {
+		return nil
+	}
+	if p.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	for _, spec := range p.ExpectedStatus {
+		if _, _, err := parseStatusRange(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseStatusRange parses "200", "200-299" or "2xx" into an inclusive range
+func parseStatusRange(spec string) (int, int, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+
+	// Class shorthand, e.g. "2xx"
+	if len(spec) == 3 && strings.HasSuffix(spec, "xx") {
+		class, err := strconv.Atoi(spec[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, fmt.Errorf("invalid status class: %q", spec)
+		}
+		return class * 100, class*100 + 99, nil
+	}
+
+	// Explicit range, e.g. "200-299"
+	if lowStr, highStr, found := strings.Cut(spec, "-"); found {
+		low, err1 := strconv.Atoi(strings.TrimSpace(lowStr))
+		high, err2 := strconv.Atoi(strings.TrimSpace(highStr))
+		if err1 != nil || err2 != nil || low > high {
+			return 0, 0, fmt.Errorf("invalid status range: %q", spec)
+		}
+		return low, high, nil
+	}
+
+	// Single status code
+	code, err := strconv.Atoi(spec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid status code: %q", spec)
+	}
+	return code, code, nil
+}
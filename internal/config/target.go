@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"apiwatcher/internal/schedule"
+	"apiwatcher/internal/wait"
+)
+
+// HAR recording modes of a target
+const (
+	HARNever     = ""        // Don't record (default)
+	HARAlways    = "always"  // Keep a HAR of every check and replay
+	HAROnFailure = "failure" // Keep a HAR of failed checks and replays only
+)
+
+// CurrentConfigVersion is the config format written by this build.
+// Version 1 stored websites as bare URL strings with per-URL option maps.
+const CurrentConfigVersion = 2
+
+// Target is a single monitored website or API with its per-site options
+type Target struct {
+	URL            string             `json:"url"`
+	Name           string             `json:"name,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
+	Owner          string             `json:"owner,omitempty"`
+	CheckType      string             `json:"check_type,omitempty"`      // CheckModeBrowser (default) or CheckModeHTTP
+	TimeoutSeconds int                `json:"timeout_seconds,omitempty"` // Overall check timeout (0 = no limit / probe default)
+	AlertEmails    []string           `json:"alert_emails,omitempty"`    // Alert routing (empty = default alert recipient)
+	HTTPProbe      *HTTPProbe         `json:"http_probe,omitempty"`      // Request options for CheckModeHTTP
+	Assertions     []Assertion        `json:"assertions,omitempty"`      // Response assertions
+	Schedule       *schedule.Schedule `json:"schedule,omitempty"`        // Check schedule (nil = worker sleep time)
+	AlertPolicy    *AlertPolicy       `json:"alert_policy,omitempty"`    // Throttling and escalation (nil = default policy)
+	HAR            string             `json:"har,omitempty"`             // HARNever, HARAlways or HAROnFailure
+	Wait           *wait.Strategy     `json:"wait,omitempty"`            // When the page counts as loaded (nil = network idle)
+}
+
+// UnmarshalJSON accepts both the structured form and a bare URL string (version 1 files)
+func (t *Target) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var rawURL string
+		if err := json.Unmarshal(data, &rawURL); err != nil {
+			return err
+		}
+		*t = Target{URL: rawURL}
+		return nil
+	}
+
+	type rawTarget Target
+	var raw rawTarget
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = Target(raw)
+	return nil
+}
+
+// DisplayName returns the target name, falling back to its URL
+func (t *Target) DisplayName() string {
+	if strings.TrimSpace(t.Name) != "" {
+		return t.Name
+	}
+	return t.URL
+}
+
+// Mode returns the check mode for the target
+func (t *Target) Mode() string {
+	if t.CheckType == CheckModeHTTP {
+		return CheckModeHTTP
+	}
+	return CheckModeBrowser
+}
+
+// Probe returns the effective HTTP probe for the target, or nil for browser checks
+func (t *Target) Probe() *HTTPProbe {
+	if t.Mode() != CheckModeHTTP {
+		return nil
+	}
+	probe := HTTPProbe{}
+	if t.HTTPProbe != nil {
+		probe = *t.HTTPProbe
+	}
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = t.TimeoutSeconds
+	}
+	return &probe
+}
+
+// KeepHAR reports whether a HAR of a check or replay with the given outcome should be stored
+func (t *Target) KeepHAR(success bool) bool {
+	switch t.HAR {
+	case HARAlways:
+		return true
+	case HAROnFailure:
+		return !success
+	default:
+		return false
+	}
+}
+
+// Timeout returns the overall check timeout (0 = no limit)
+func (t *Target) Timeout() time.Duration {
+	if t.TimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(t.TimeoutSeconds) * time.Second
+}
+
+// ValidateTarget validates target fields
+func ValidateTarget(t Target) error {
+	if strings.TrimSpace(t.URL) == "" {
+		return fmt.Errorf("target URL is required")
+	}
+	if _, err := url.Parse(t.URL); err != nil {
+		return fmt.Errorf("invalid target URL: %w", err)
+	}
+	if t.CheckType != "" && t.CheckType != CheckModeBrowser && t.CheckType != CheckModeHTTP {
+		return fmt.Errorf("unknown check type: %q", t.CheckType)
+	}
+	if t.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if t.HAR != HARNever && t.HAR != HARAlways && t.HAR != HAROnFailure {
+		return fmt.Errorf("unknown HAR mode: %q", t.HAR)
+	}
+	if t.Wait != nil {
+		if err := t.Wait.Validate(); err != nil {
+			return fmt.Errorf("invalid wait: %w", err)
+		}
+	}
+	for _, email := range t.AlertEmails {
+		if !strings.Contains(email, "@") {
+			return fmt.Errorf("invalid alert email address: %q", email)
+		}
+	}
+	if err := ValidateHTTPProbe(t.HTTPProbe); err != nil {
+		return fmt.Errorf("invalid HTTP probe: %w", err)
+	}
+	for i, a := range t.Assertions {
+		if err := ValidateAssertion(a); err != nil {
+			return fmt.Errorf("invalid assertion %d: %w", i+1, err)
+		}
+	}
+	if err := t.Schedule.Validate(); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	if err := ValidateAlertPolicy(t.AlertPolicy); err != nil {
+		return fmt.Errorf("invalid alert policy: %w", err)
+	}
+	return nil
+}
+
+// TargetsFromURLs builds plain browser-check targets from bare URLs
+func TargetsFromURLs(urls []string) []Target {
+	targets := make([]Target, 0, len(urls))
+	for _, u := range urls {
+		targets = append(targets, Target{URL: u})
+	}
+	return targets
+}
+
+// TargetURLs returns the URLs of a target list
+func TargetURLs(targets []Target) []string {
+	urls := make([]string, 0, len(targets))
+	for _, t := range targets {
+		urls = append(urls, t.URL)
+	}
+	return urls
+}
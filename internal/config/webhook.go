@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Webhook payload formats
+const (
+	WebhookFormatJSON  = "json"  // Alert as JSON (default)
+	WebhookFormatSlack = "slack" // Slack incoming webhook (Block Kit)
+	WebhookFormatTeams = "teams" // Microsoft Teams incoming webhook (MessageCard)
+)
+
+// WebhookConfig represents a webhook alert channel
+type WebhookConfig struct {
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Format       string            `json:"format,omitempty"`        // WebhookFormatJSON (default), WebhookFormatSlack or WebhookFormatTeams
+	Headers      map[string]string `json:"headers,omitempty"`       // Extra request headers (e.g. Authorization)
+	BodyTemplate string            `json:"body_template,omitempty"` // Go text/template for the body (empty = alert as JSON)
+	Secret       string            `json:"secret,omitempty"`        // HMAC-SHA256 signing secret (empty = unsigned)
+	MaxRetries   int               `json:"max_retries,omitempty"`   // Retries after the first attempt (0 = default, negative = none)
+	Disabled     bool              `json:"disabled,omitempty"`
+}
+
+// WebhookTemplateFuncs are the helper functions available in webhook body templates
+var WebhookTemplateFuncs = template.FuncMap{
+	// json renders a value as JSON, e.g. {"text": {{json .Subject}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// GetWebhooksConfigPath returns the path to the webhook configuration file
+func GetWebhooksConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".apiwatcher", "webhooks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create webhook config directory: %w", err)
+	}
+	return filepath.Join(dir, "webhooks.json"), nil
+}
+
+// LoadWebhooks loads all configured webhooks
+func LoadWebhooks() ([]*WebhookConfig, error) {
+	path, err := GetWebhooksConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*WebhookConfig{}, nil // No webhooks configured yet
+		}
+		return nil, fmt.Errorf("failed to read webhook config: %w", err)
+	}
+
+	var webhooks []*WebhookConfig
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook config: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// SaveWebhooks saves the full webhook list
+func SaveWebhooks(webhooks []*WebhookConfig) error {
+	path, err := GetWebhooksConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(webhooks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook config: %w", err)
+	}
+
+	// Secrets are stored here, so keep the file private like the SMTP config
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write webhook config: %w", err)
+	}
+
+	return nil
+}
+
+// SaveWebhook adds a webhook or replaces the existing one with the same name
+func SaveWebhook(webhook *WebhookConfig) error {
+	webhooks, err := LoadWebhooks()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, existing := range webhooks {
+		if existing.Name == webhook.Name {
+			webhooks[i] = webhook
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		webhooks = append(webhooks, webhook)
+	}
+
+	return SaveWebhooks(webhooks)
+}
+
+// DeleteWebhook removes a webhook by name
+func DeleteWebhook(name string) error {
+	webhooks, err := LoadWebhooks()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]*WebhookConfig, 0, len(webhooks))
+	for _, existing := range webhooks {
+		if existing.Name != name {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(webhooks) {
+		return fmt.Errorf("webhook not found: %s", name)
+	}
+
+	return SaveWebhooks(remaining)
+}
+
+// ValidateWebhookConfig validates webhook configuration fields
+func ValidateWebhookConfig(webhook *WebhookConfig) error {
+	if webhook == nil {
+		return fmt.Errorf("webhook config is nil")
+	}
+
+	if strings.TrimSpace(webhook.Name) == "" {
+		return fmt.Errorf("webhook name is required")
+	}
+
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute http(s) URL")
+	}
+
+	switch webhook.Format {
+	case "", WebhookFormatJSON, WebhookFormatSlack, WebhookFormatTeams:
+	default:
+		return fmt.Errorf("unknown webhook format: %q", webhook.Format)
+	}
+
+	if webhook.BodyTemplate != "" {
+		if _, err := template.New("body").Funcs(WebhookTemplateFuncs).Parse(webhook.BodyTemplate); err != nil {
+			return fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package daemon
+
+import (
+	"apiwatcher/internal/artifact"
+	"apiwatcher/internal/monitor"
+)
+
+// saveReplayArtifacts stores the screenshots and DOMs captured at failed replay steps
+func (d *Daemon) saveReplayArtifacts(url string, runs []monitor.SnapshotRun) {
+	if d.artifacts == nil {
+		return
+	}
+	for _, run := range runs {
+		if run.Result == nil {
+			continue
+		}
+		for _, failure := range run.Result.Failures {
+			info := artifact.Info{
+				ID:          failure.ID,
+				Target:      url,
+				SnapshotID:  run.SnapshotID,
+				Version:     run.Version,
+				ActionIndex: failure.ActionIndex,
+				ActionType:  failure.ActionType,
+				Selector:    failure.Selector,
+				Error:       failure.Error,
+				PageURL:     failure.PageURL,
+				CreatedAt:   failure.CapturedAt,
+			}
+			if _, err := d.artifacts.Save(info, failure.Screenshot, failure.DOM); err != nil {
+				d.Logf("[ARTIFACT] Failed to save failure artifact for %s: %v", url, err)
+				continue
+			}
+			d.Logf("[ARTIFACT] 📸 Saved failure artifact %s for %s (%s)", failure.ID, url, failure.Describe())
+		}
+	}
+}
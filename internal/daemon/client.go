@@ -1,6 +1,12 @@
 package daemon
 
 import (
+	"apiwatcher/internal/alert"
+	"apiwatcher/internal/artifact"
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/schedule"
+	"apiwatcher/internal/snapshot"
 	"bufio"
 	"encoding/json"
 	"fmt"
@@ -124,10 +130,10 @@ func (c *Client) Stop() error {
 }
 
 // SetConfig sets the daemon configuration
-func (c *Client) SetConfig(email string, websites []string, snapshotIDs map[string]string) error {
+func (c *Client) SetConfig(email string, targets []config.Target, snapshotIDs map[string]string) error {
 	payload := SetConfigPayload{
 		Email:       email,
-		Websites:    websites,
+		Websites:    targets,
 		SnapshotIDs: snapshotIDs,
 	}
 
@@ -149,6 +155,30 @@ func (c *Client) SetConfig(email string, websites []string, snapshotIDs map[stri
 	return nil
 }
 
+// GetConfig gets the daemon's current monitoring configuration
+func (c *Client) GetConfig() (*config.Config, error) {
+	resp, err := c.SendCommand(Command{Type: CmdGetConfig})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("failed to get config: %s", resp.Message)
+	}
+
+	// Convert data to config.Config
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var cfg config.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
 // GetLogs gets the last N log lines
 func (c *Client) GetLogs(n int) ([]string, error) {
 	payload := GetLogsPayload{Lines: n}
@@ -247,6 +277,250 @@ func (c *Client) GetSMTP() (map[string]string, error) {
 	return smtpData, nil
 }
 
+// SetWebhook adds or updates a webhook alert channel on the daemon
+func (c *Client) SetWebhook(webhook config.WebhookConfig) error {
+	payloadJSON, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.SendCommand(Command{
+		Type:    CmdSetWebhook,
+		Payload: payloadJSON,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("failed to set webhook: %s", resp.Message)
+	}
+	return nil
+}
+
+// GetWebhooks gets the configured webhooks from the daemon (without secrets)
+func (c *Client) GetWebhooks() ([]WebhookInfo, error) {
+	resp, err := c.SendCommand(Command{Type: CmdGetWebhooks})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("failed to get webhooks: %s", resp.Message)
+	}
+
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var webhooks []WebhookInfo
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook from the daemon
+func (c *Client) DeleteWebhook(name string) error {
+	return c.sendWebhookName(CmdDeleteWebhook, name, "failed to delete webhook")
+}
+
+// TestWebhook asks the daemon to deliver a test alert to a webhook
+func (c *Client) TestWebhook(name string) error {
+	return c.sendWebhookName(CmdTestWebhook, name, "failed to test webhook")
+}
+
+func (c *Client) sendWebhookName(cmdType, name, failure string) error {
+	payloadJSON, err := json.Marshal(WebhookNamePayload{Name: name})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := c.SendCommand(Command{
+		Type:    cmdType,
+		Payload: payloadJSON,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s: %s", failure, resp.Message)
+	}
+	return nil
+}
+
+// AckAlert acknowledges the open incident for a target, stopping repeats and escalation
+func (c *Client) AckAlert(target, by string) (*alert.Incident, error) {
+	var incident alert.Incident
+	if err := c.sendDataCommand(CmdAckAlert, AckAlertPayload{Target: target, By: by}, &incident, "failed to acknowledge alert"); err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// ListIncidents gets the open alert incidents from the daemon
+func (c *Client) ListIncidents() ([]alert.Incident, error) {
+	var incidents []alert.Incident
+	if err := c.sendDataCommand(CmdListIncidents, nil, &incidents, "failed to list incidents"); err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// Silence suppresses alerts for a target ("*" for all) for the given duration
+func (c *Client) Silence(target string, duration time.Duration, reason string) (*alert.Silence, error) {
+	payload := SilencePayload{
+		Target:          target,
+		DurationMinutes: int(duration / time.Minute),
+		Reason:          reason,
+	}
+	var silence alert.Silence
+	if err := c.sendDataCommand(CmdSilence, payload, &silence, "failed to silence"); err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// Unsilence removes a silence by ID
+func (c *Client) Unsilence(id string) error {
+	return c.sendDataCommand(CmdUnsilence, UnsilencePayload{ID: id}, nil, "failed to remove silence")
+}
+
+// ListSilences gets the active silences from the daemon
+func (c *Client) ListSilences() ([]alert.Silence, error) {
+	var silences []alert.Silence
+	if err := c.sendDataCommand(CmdListSilences, nil, &silences, "failed to list silences"); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// SetMaintenanceWindow adds or updates a maintenance window
+func (c *Client) SetMaintenanceWindow(window schedule.MaintenanceWindow) (*schedule.MaintenanceWindow, error) {
+	var saved schedule.MaintenanceWindow
+	if err := c.sendDataCommand(CmdSetMaintenance, window, &saved, "failed to save maintenance window"); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// ListMaintenanceWindows gets the maintenance windows from the daemon
+func (c *Client) ListMaintenanceWindows() ([]schedule.MaintenanceWindow, error) {
+	var windows []schedule.MaintenanceWindow
+	if err := c.sendDataCommand(CmdListMaintenance, nil, &windows, "failed to list maintenance windows"); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// DeleteMaintenanceWindow removes a maintenance window by ID
+func (c *Client) DeleteMaintenanceWindow(id string) error {
+	return c.sendDataCommand(CmdDeleteMaintenance, DeleteMaintenancePayload{ID: id}, nil, "failed to delete maintenance window")
+}
+
+// GetHistory gets a website's check history, raw or downsampled to the given resolution (e.g. "1h")
+func (c *Client) GetHistory(target string, from, to time.Time, resolution string) (*HistoryData, error) {
+	payload := GetHistoryPayload{
+		Target:     target,
+		From:       from,
+		To:         to,
+		Resolution: resolution,
+	}
+	var data HistoryData
+	if err := c.sendDataCommand(CmdGetHistory, payload, &data, "failed to get history"); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ListHAR lists the recorded HAR sessions of a website (every website if empty), newest first
+func (c *Client) ListHAR(target string) ([]har.Info, error) {
+	var recordings []har.Info
+	if err := c.sendDataCommand(CmdListHAR, ListHARPayload{Target: target}, &recordings, "failed to list HAR recordings"); err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
+
+// GetHAR gets a recorded HAR session by ID
+func (c *Client) GetHAR(id string) (*HARData, error) {
+	var data HARData
+	if err := c.sendDataCommand(CmdGetHAR, GetHARPayload{ID: id}, &data, "failed to get HAR recording"); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ListArtifacts lists the failure artifacts of a website (every website if empty), newest first
+func (c *Client) ListArtifacts(target string) ([]artifact.Info, error) {
+	var artifacts []artifact.Info
+	if err := c.sendDataCommand(CmdListArtifacts, ListArtifactsPayload{Target: target}, &artifacts, "failed to list artifacts"); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// GetArtifact gets a failure artifact with its screenshot and DOM by ID
+func (c *Client) GetArtifact(id string) (*ArtifactData, error) {
+	var data ArtifactData
+	if err := c.sendDataCommand(CmdGetArtifact, GetArtifactPayload{ID: id}, &data, "failed to get artifact"); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// ImportSnapshots imports snapshots on the daemon from a bundle, a Chrome Recorder
+// recording or a script (see snapshot.Import)
+func (c *Client) ImportSnapshots(format string, data []byte, overwrite bool) (*snapshot.ImportResult, error) {
+	var result snapshot.ImportResult
+	payload := ImportSnapshotsPayload{Format: format, Data: data, Overwrite: overwrite}
+	if err := c.sendDataCommand(CmdImportSnapshots, payload, &result, "failed to import snapshots"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportSnapshots exports the daemon's snapshots with the given IDs (all if empty) as a bundle
+func (c *Client) ExportSnapshots(ids []string) ([]byte, error) {
+	var data ExportData
+	if err := c.sendDataCommand(CmdExportSnapshots, ExportSnapshotsPayload{IDs: ids}, &data, "failed to export snapshots"); err != nil {
+		return nil, err
+	}
+	return data.Bundle, nil
+}
+
+// sendDataCommand sends a command and decodes the response data into out (if not nil)
+func (c *Client) sendDataCommand(cmdType string, payload interface{}, out interface{}, failure string) error {
+	cmd := Command{Type: cmdType}
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		cmd.Payload = payloadJSON
+	}
+
+	resp, err := c.SendCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("%s: %s", failure, resp.Message)
+	}
+	if out == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+	return nil
+}
+
 // GetWebsiteStats gets statistics for all monitored websites
 func (c *Client) GetWebsiteStats() ([]WebsiteStatsResponse, error) {
 	resp, err := c.SendCommand(Command{Type: CmdGetWebsiteStats})
@@ -5,13 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
+	"apiwatcher/internal/alert"
+	"apiwatcher/internal/artifact"
 	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/history"
 	"apiwatcher/internal/monitor"
+	"apiwatcher/internal/schedule"
 	"apiwatcher/internal/snapshot"
 )
 
@@ -30,7 +37,6 @@ type Daemon struct {
 	state             State
 	config            *config.Config
 	snapshotsByURL    map[string][]*snapshot.Snapshot // Multiple snapshots per URL
-	jobQueue          chan monitor.Job
 	stopChan          chan bool
 	mutex             sync.RWMutex
 	logBuffer         *LogBuffer
@@ -41,6 +47,14 @@ type Daemon struct {
 	jobWaitGroup      sync.WaitGroup
 	monitoringStopped chan bool
 	cancelCtx         context.CancelFunc
+	inFlight          map[string]bool // Checks and snapshot replays currently queued or running
+	inFlightMutex     sync.Mutex
+	silences          *alert.SilenceStore        // Alert silences persisted in the data dir
+	incidents         *alert.IncidentStore       // Open alert incidents persisted in the data dir
+	maintenance       *schedule.MaintenanceStore // Maintenance windows persisted in the data dir
+	history           *history.Store             // Durable per-website check history
+	harStore          *har.Store                 // Recorded HAR sessions of checks and replays
+	artifacts         *artifact.Store            // Screenshots and DOMs of failed replay steps
 }
 
 // Stats holds monitoring statistics
@@ -82,9 +96,12 @@ type WebsiteStats struct {
 	// Uptime tracking
 	UptimeLastHour       float64 // Percentage
 	UptimeLast24Hours    float64 // Percentage
-	UptimeLast7Days      float64 // Percentage
+	UptimeLast7Days      float64 // Percentage, from the history store when there is one
 	OverallHealthPercent float64 // Total success rate
 
+	// When UptimeLast7Days was last read from the history store
+	weeklyUptimeAt time.Time
+
 	// Downtime tracking
 	LastDowntimeStart    time.Time
 	LastDowntimeEnd      time.Time
@@ -95,20 +112,30 @@ type WebsiteStats struct {
 	// Alert tracking
 	LastAlertSent time.Time
 
+	// Whether the latest check ran inside a maintenance window
+	InMaintenance bool
+
+	// Metrics counters (exported on /metrics)
+	DurationHistogram      []int          // Checks per checkDurationBuckets bound, plus a final +Inf slot
+	DurationSum            time.Duration  // Sum of all check durations
+	FailedRequestsByStatus map[int]int    // Failed API calls by HTTP status code
+	SnapshotOutcomes       map[string]int // Snapshot replays by outcome (passed, api_errors, failed)
+
 	// Health trend
 	HealthTrend string // "improving", "stable", "degrading"
 
-	// Track recent checks for time-window calculations
-	CheckHistory []CheckRecord // Recent checks for uptime calculations
+	// Track recent checks for time-window calculations (rebuilt from the history store on load)
+	CheckHistory []CheckRecord `json:"-"`
 
 	mutex sync.RWMutex
 }
 
 // CheckRecord represents a single check result for uptime calculations
 type CheckRecord struct {
-	Timestamp time.Time
-	Success   bool
-	Duration  time.Duration
+	Timestamp   time.Time
+	Success     bool
+	Duration    time.Duration
+	Maintenance bool // Ran inside a maintenance window (excluded from uptime)
 }
 
 // WebsiteStatsMap manages statistics for all monitored websites
@@ -127,8 +154,10 @@ type DaemonState struct {
 	LastSaved    time.Time                `json:"last_saved"`
 }
 
-// New creates a new daemon instance
-func New(dataDir string) (*Daemon, error) {
+// New creates a new daemon instance. historyRetention is how long check history
+// is kept (history.DefaultRetention if 0); it is applied before old history is
+// pruned on open.
+func New(dataDir string, historyRetention time.Duration) (*Daemon, error) {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
@@ -142,11 +171,43 @@ func New(dataDir string) (*Daemon, error) {
 		websiteStats: &WebsiteStatsMap{
 			stats: make(map[string]*WebsiteStats),
 		},
-		dataDir: dataDir,
+		dataDir:  dataDir,
+		inFlight: make(map[string]bool),
 	}
 
+	checkHistory, err := history.Open(filepath.Join(dataDir, "history"), historyRetention)
+	if err != nil {
+		log.Printf("Failed to open check history: %v", err)
+	}
+	d.history = checkHistory
+
 	_ = d.loadState() // silently ignore load errors
 
+	silences, err := alert.NewSilenceStore(filepath.Join(dataDir, "silences.json"))
+	if err != nil {
+		log.Printf("Failed to load silences: %v", err)
+	}
+	d.silences = silences
+	d.incidents = alert.NewIncidentStore(filepath.Join(dataDir, "incidents.json"))
+
+	maintenance, err := schedule.NewMaintenanceStore(filepath.Join(dataDir, "maintenance.json"))
+	if err != nil {
+		log.Printf("Failed to load maintenance windows: %v", err)
+	}
+	d.maintenance = maintenance
+
+	harStore, err := har.OpenStore(filepath.Join(dataDir, "har"))
+	if err != nil {
+		log.Printf("Failed to open HAR store: %v", err)
+	}
+	d.harStore = harStore
+
+	artifacts, err := artifact.OpenStore(filepath.Join(dataDir, "artifacts"))
+	if err != nil {
+		log.Printf("Failed to open artifact store: %v", err)
+	}
+	d.artifacts = artifacts
+
 	return d, nil
 }
 
@@ -210,6 +271,10 @@ func (d *Daemon) GetAllWebsiteStats() map[string]*WebsiteStats {
 		// Return a copy to avoid race conditions
 		stats.mutex.RLock()
 		statsCopy := *stats
+		// Maps and the histogram are updated in place, so they are copied too
+		statsCopy.FailedRequestsByStatus = maps.Clone(stats.FailedRequestsByStatus)
+		statsCopy.SnapshotOutcomes = maps.Clone(stats.SnapshotOutcomes)
+		statsCopy.DurationHistogram = slices.Clone(stats.DurationHistogram)
 		stats.mutex.RUnlock()
 		result[url] = &statsCopy
 	}
@@ -300,11 +365,25 @@ func (d *Daemon) loadState() error {
 			}
 		}
 		d.websiteStats.mutex.Unlock()
+
+		// Older state files carried the check history inline
+		var legacy struct {
+			WebsiteStats map[string]struct {
+				CheckHistory []CheckRecord
+			} `json:"website_stats"`
+		}
+		legacyHistory := make(map[string][]CheckRecord)
+		if err := json.Unmarshal(data, &legacy); err == nil {
+			for url, stats := range legacy.WebsiteStats {
+				legacyHistory[url] = stats.CheckHistory
+			}
+		}
+		d.restoreCheckHistory(legacyHistory)
 	}
 
 	// Load ALL snapshots for configured URLs
 	if d.config != nil && d.config.Websites != nil {
-		for _, url := range d.config.Websites {
+		for _, url := range d.config.URLs() {
 			snaps, _ := snapshot.LoadForURL(url)
 			if snaps != nil && len(snaps) > 0 {
 				d.snapshotsByURL[url] = snaps
@@ -457,125 +536,64 @@ func (d *Daemon) runMonitoring(ctx context.Context) {
 		d.mutex.Unlock()
 	}()
 
+	// Local cancel so the schedulers of this session can be stopped independently
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	const numWorkers = 30
-	d.jobQueue = make(chan monitor.Job, 100)
+	jobQueue := make(chan monitor.Job, 100)
+	snapshotQueue := make(chan string, 100)
+	stopChan := d.stopChan
 
 	// Start workers with context
 	for i := 1; i <= numWorkers; i++ {
-		go d.worker(ctx, i)
+		go d.worker(ctx, i, jobQueue, snapshotQueue)
 	}
 
-	for {
-		select {
-		case <-d.stopChan:
-			d.Logf("Stop signal received, shutting down monitoring loop")
-			close(d.jobQueue)
-			return
-		case <-ctx.Done():
-			d.Logf("Context cancelled, shutting down monitoring loop")
-			close(d.jobQueue)
-			return
-		default:
-		}
-
-		// Load SMTP config to get alert email
-		smtpConfig, _ := config.LoadSMTPConfig()
-		alertEmail := d.config.Email // Fallback to config email
-		if smtpConfig != nil && smtpConfig.To != "" {
-			alertEmail = smtpConfig.To
-		}
-
-		// ============ PHASE 1: API Checks (Parallel with 30 workers) ============
-		websiteCount := len(d.config.Websites)
-		d.Logf("[PHASE 1] Queueing %d API check jobs", websiteCount)
-		d.jobWaitGroup.Add(websiteCount)
-
-		for _, site := range d.config.Websites {
-			// Use legacy Job structure for compatibility with existing worker
-			job := monitor.Job{
-				Website:  site,
-				Email:    alertEmail,
-				Snapshot: nil, // No snapshots in Phase 1
-			}
-
-			select {
-			case d.jobQueue <- job:
-			case <-d.stopChan:
-				d.jobWaitGroup.Done()
-				break
-			}
-		}
-
-		// Wait for all API checks to complete
-		d.jobWaitGroup.Wait()
-		d.Logf("[PHASE 1] All API checks completed")
-
-		d.stats.mutex.Lock()
-		d.stats.LastCheckTime = time.Now()
-		d.stats.mutex.Unlock()
-
-		// ============ PHASE 2: Snapshots (Sequential - one at a time) ============
-		d.Logf("[PHASE 2] Starting snapshot replay phase")
-		for _, site := range d.config.Websites {
-			// Check if snapshots exist for this website
-			snapshotList := d.snapshotsByURL[site]
-			if snapshotList == nil || len(snapshotList) == 0 {
-				continue
-			}
-
-			// Run snapshots sequentially (one at a time to avoid RAM issues)
-			snapJob := monitor.SnapshotJob{
-				Website:   site,
-				Email:     alertEmail,
-				Snapshots: snapshotList,
-			}
-			monitor.ProcessSnapshots(snapJob, d)
-		}
-		d.Logf("[PHASE 2] Snapshot replay phase completed")
-
-		// Reload settings on each cycle to pick up any changes
-		_ = config.LoadSettings()
-		sleepTime := time.Duration(config.GetWorkerSleepTime()) * time.Minute
-		d.Logf("Sleeping for %v", sleepTime)
+	// Snapshots run sequentially (one at a time to avoid RAM issues)
+	go d.snapshotRunner(snapshotQueue)
+
+	// Every website gets its own scheduler so sites can be checked at different rates
+	var schedulers sync.WaitGroup
+	d.Logf("[SCHEDULER] Starting schedulers for %d websites", len(d.config.Websites))
+	for _, target := range d.config.Websites {
+		schedulers.Add(1)
+		go func(target config.Target) {
+			defer schedulers.Done()
+			d.scheduleWebsite(ctx, target, jobQueue)
+		}(target)
+	}
 
-		select {
-		case <-time.After(sleepTime):
-		case <-d.stopChan:
-			d.Logf("Stop signal received, shutting down monitoring loop")
-			close(d.jobQueue)
-			return
-		case <-ctx.Done():
-			d.Logf("Context cancelled, shutting down monitoring loop")
-			close(d.jobQueue)
-			return
-		}
+	select {
+	case <-stopChan:
+		d.Logf("Stop signal received, shutting down monitoring loop")
+	case <-ctx.Done():
+		d.Logf("Context cancelled, shutting down monitoring loop")
 	}
+
+	// Schedulers exit on context cancellation; only then is it safe to close the queues
+	cancel()
+	schedulers.Wait()
+	close(jobQueue)
+	d.jobWaitGroup.Wait()
+	close(snapshotQueue)
 }
 
-func (d *Daemon) worker(ctx context.Context, id int) {
-	for job := range d.jobQueue {
+func (d *Daemon) worker(ctx context.Context, id int, jobs <-chan monitor.Job, snapshotQueue chan<- string) {
+	for job := range jobs {
 		// Check if context is cancelled (instant abort)
 		select {
 		case <-ctx.Done():
-			d.Logf("[Worker %d] Context cancelled, aborting", id)
-			d.jobWaitGroup.Done()
-			return
-		default:
-		}
-
-		// Also check stop signal
-		select {
-		case <-d.stopChan:
-			d.Logf("[Worker %d] Stop signal received, exiting", id)
-			d.jobWaitGroup.Done()
-			return
+			d.Logf("[Worker %d] Context cancelled, aborting job for %s", id, job.Website)
+			d.finishJob(job.Website)
+			continue
 		default:
 		}
 
 		// Check monitoringActive flag
 		if !d.monitoringActive {
 			d.Logf("[Worker %d] Monitoring inactive, skipping job", id)
-			d.jobWaitGroup.Done()
+			d.finishJob(job.Website)
 			continue
 		}
 
@@ -583,20 +601,42 @@ func (d *Daemon) worker(ctx context.Context, id int) {
 		d.stats.TotalChecks++
 		d.stats.mutex.Unlock()
 
+		// Results inside a maintenance window are recorded but never alert
+		job.Maintenance = d.inMaintenance(job.Website)
+		job.RecordHAR = d.harMode(job.Website) != config.HARNever
+
 		// Pass context to ProcessJob so it can abort mid-operation
 		result := monitor.ProcessJob(ctx, id, job, d)
 
 		// Update global stats
-		if !result.Success {
-			d.stats.mutex.Lock()
+		d.stats.mutex.Lock()
+		if !result.Success && !job.Maintenance {
 			d.stats.FailedChecks++
-			d.stats.mutex.Unlock()
 		}
+		if result.AlertSent {
+			d.stats.AlertsSent++
+		}
+		d.stats.LastCheckTime = time.Now()
+		d.stats.mutex.Unlock()
 
 		// Update per-website stats
-		d.UpdateWebsiteStats(job.Website, result.Success, result.Duration, result.AlertSent)
+		d.UpdateWebsiteStats(ctx, job.Website, result.Success, result.Duration, result.AlertSent, job.Maintenance)
+		d.recordHistory(job.Website, result, job.Maintenance)
+		d.refreshWeeklyUptime(job.Website, d.GetOrCreateWebsiteStats(job.Website))
+		d.recordCheckMetrics(job.Website, result)
+		d.saveHAR(har.Info{Target: job.Website, Kind: har.KindCheck, Success: result.Success}, result.HAR)
+
+		// Queue snapshot replays for this website after its check
+		if ctx.Err() == nil && len(d.getSnapshots(job.Website)) > 0 && d.markInFlight("snapshot:"+job.Website) {
+			select {
+			case snapshotQueue <- job.Website:
+			default:
+				d.Logf("[SNAPSHOTS] Replay queue full, skipping snapshots for %s", job.Website)
+				d.clearInFlight("snapshot:" + job.Website)
+			}
+		}
 
-		d.jobWaitGroup.Done()
+		d.finishJob(job.Website)
 	}
 
 	d.Logf("[Worker %d] Job queue closed, exiting", id)
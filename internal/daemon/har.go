@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/monitor"
+)
+
+// harMode returns the HAR recording mode of a monitored URL
+func (d *Daemon) harMode(url string) string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if t := d.config.Target(url); t != nil {
+		return t.HAR
+	}
+	return config.HARNever
+}
+
+// saveHAR stores a recorded session if the website's HAR mode keeps this outcome
+func (d *Daemon) saveHAR(info har.Info, recording *har.HAR) {
+	if recording == nil || d.harStore == nil {
+		return
+	}
+	target := config.Target{HAR: d.harMode(info.Target)}
+	if !target.KeepHAR(info.Success) {
+		return
+	}
+
+	saved, err := d.harStore.Save(info, recording)
+	if err != nil {
+		d.Logf("[HAR] Failed to save %s HAR for %s: %v", info.Kind, info.Target, err)
+		return
+	}
+	d.Logf("[HAR] 📼 Saved %s HAR for %s (%d requests, ID: %s)", info.Kind, info.Target, saved.Entries, saved.ID)
+}
+
+// saveReplayHARs stores the recorded sessions of snapshot replays
+func (d *Daemon) saveReplayHARs(url string, runs []monitor.SnapshotRun) {
+	for _, run := range runs {
+		if run.Result == nil || run.Result.HAR == nil {
+			continue
+		}
+		d.saveHAR(har.Info{
+			Target:     url,
+			Kind:       har.KindReplay,
+			SnapshotID: run.SnapshotID,
+			Version:    run.Version,
+			Success:    run.Outcome() == monitor.SnapshotPassed,
+		}, run.Result.HAR)
+	}
+}
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"time"
+
+	"apiwatcher/internal/history"
+	"apiwatcher/internal/monitor"
+)
+
+// checkHistoryWindow is how much stored history is kept in memory for uptime calculations,
+// up to checkHistoryLimit checks. The 7 day uptime is read from the store instead every
+// weeklyUptimeRefresh, since busy websites run more checks than that in a week.
+const (
+	checkHistoryWindow  = 7 * 24 * time.Hour
+	checkHistoryLimit   = 2000
+	weeklyUptimeRefresh = 5 * time.Minute
+)
+
+// recordHistory appends a check result to the durable history store
+func (d *Daemon) recordHistory(url string, result monitor.JobResult, maintenance bool) {
+	if d.history == nil {
+		return
+	}
+
+	record := history.Record{
+		Timestamp:   time.Now(),
+		Success:     result.Success,
+		Duration:    result.Duration,
+		Maintenance: maintenance,
+		ErrorCount:  result.ErrorCount,
+	}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+	for _, req := range result.Requests {
+		record.Requests = append(record.Requests, history.NewRequest(req))
+	}
+
+	if err := d.history.Append(url, record); err != nil {
+		d.Logf("[HISTORY] Failed to record check for %s: %v", url, err)
+	}
+}
+
+// restoreCheckHistory rebuilds the in-memory check history of every website from
+// the history store. legacy holds histories from state files written before the
+// store existed; they are imported once for websites without stored history.
+func (d *Daemon) restoreCheckHistory(legacy map[string][]CheckRecord) {
+	if d.history == nil {
+		return
+	}
+
+	d.websiteStats.mutex.RLock()
+	defer d.websiteStats.mutex.RUnlock()
+
+	since := time.Now().Add(-checkHistoryWindow)
+	for url, stats := range d.websiteStats.stats {
+		records, err := d.history.Last(url, since, checkHistoryLimit)
+		if err != nil {
+			d.Logf("[HISTORY] Failed to load history for %s: %v", url, err)
+			continue
+		}
+
+		if len(records) == 0 && len(legacy[url]) > 0 {
+			for _, check := range legacy[url] {
+				record := history.Record{
+					Timestamp:   check.Timestamp,
+					Success:     check.Success,
+					Duration:    check.Duration,
+					Maintenance: check.Maintenance,
+				}
+				if err := d.history.Append(url, record); err != nil {
+					d.Logf("[HISTORY] Failed to import history for %s: %v", url, err)
+					break
+				}
+			}
+			records, _ = d.history.Last(url, since, checkHistoryLimit)
+		}
+
+		checks := make([]CheckRecord, 0, len(records))
+		for _, r := range records {
+			checks = append(checks, CheckRecord{
+				Timestamp:   r.Timestamp,
+				Success:     r.Success,
+				Duration:    r.Duration,
+				Maintenance: r.Maintenance,
+			})
+		}
+
+		stats.mutex.Lock()
+		stats.CheckHistory = checks
+		stats.mutex.Unlock()
+		d.refreshWeeklyUptime(url, stats)
+	}
+}
+
+// refreshWeeklyUptime recomputes the 7 day uptime of a website from the history store
+// with a single aggregate over the week, at most every weeklyUptimeRefresh
+func (d *Daemon) refreshWeeklyUptime(url string, stats *WebsiteStats) {
+	if d.history == nil {
+		return
+	}
+
+	now := time.Now()
+	stats.mutex.Lock()
+	due := now.Sub(stats.weeklyUptimeAt) >= weeklyUptimeRefresh
+	stats.mutex.Unlock()
+	if !due {
+		return
+	}
+
+	from := now.Add(-checkHistoryWindow)
+	records, err := d.history.Query(url, from, now)
+	if err != nil {
+		d.Logf("[HISTORY] Failed to load history for %s: %v", url, err)
+		return
+	}
+	uptime := 100.0
+	if week := history.Downsample(records, from, now, checkHistoryWindow); len(week) == 1 && week[0].SuccessRatio != nil {
+		uptime = *week[0].SuccessRatio * 100
+	}
+
+	stats.mutex.Lock()
+	stats.UptimeLast7Days = uptime
+	stats.weeklyUptimeAt = now
+	stats.mutex.Unlock()
+}
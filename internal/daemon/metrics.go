@@ -0,0 +1,256 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"apiwatcher/internal/monitor"
+)
+
+// checkDurationBuckets are the upper bounds (seconds) of the check duration histogram
+var checkDurationBuckets = []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// ==========================
+// Metric Recording
+// ==========================
+
+// recordCheckMetrics updates the metric counters of a website after a check
+func (d *Daemon) recordCheckMetrics(url string, result monitor.JobResult) {
+	stats := d.GetOrCreateWebsiteStats(url)
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	if len(stats.DurationHistogram) != len(checkDurationBuckets)+1 {
+		stats.DurationHistogram = make([]int, len(checkDurationBuckets)+1)
+	}
+	seconds := result.Duration.Seconds()
+	slot := sort.SearchFloat64s(checkDurationBuckets, seconds)
+	stats.DurationHistogram[slot]++
+	stats.DurationSum += result.Duration
+
+	if len(result.BadRequests) > 0 && stats.FailedRequestsByStatus == nil {
+		stats.FailedRequestsByStatus = make(map[int]int)
+	}
+	for _, r := range result.BadRequests {
+		stats.FailedRequestsByStatus[r.StatusCode]++
+	}
+}
+
+// recordSnapshotRuns counts snapshot replay outcomes for a website
+func (d *Daemon) recordSnapshotRuns(url string, runs []monitor.SnapshotRun) {
+	if len(runs) == 0 {
+		return
+	}
+
+	stats := d.GetOrCreateWebsiteStats(url)
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	if stats.SnapshotOutcomes == nil {
+		stats.SnapshotOutcomes = make(map[string]int)
+	}
+	for _, run := range runs {
+		stats.SnapshotOutcomes[run.Outcome()]++
+	}
+}
+
+// ==========================
+// Prometheus Exposition
+// ==========================
+
+// WriteMetrics writes all daemon and per-website metrics in the Prometheus text format
+func (d *Daemon) WriteMetrics(w io.Writer) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	// Daemon-wide counters
+	d.stats.mutex.RLock()
+	totalChecks, failedChecks, alertsSent := d.stats.TotalChecks, d.stats.FailedChecks, d.stats.AlertsSent
+	d.stats.mutex.RUnlock()
+
+	running := 0
+	if d.GetState() == StateRunning {
+		running = 1
+	}
+
+	writeHeader(bw, "apiwatcher_monitoring_active", "gauge", "Whether monitoring is running (1) or not (0).")
+	fmt.Fprintf(bw, "apiwatcher_monitoring_active %d\n", running)
+	writeHeader(bw, "apiwatcher_checks_total", "counter", "Checks run since monitoring started.")
+	fmt.Fprintf(bw, "apiwatcher_checks_total %d\n", totalChecks)
+	writeHeader(bw, "apiwatcher_checks_failed_total", "counter", "Failed checks since monitoring started.")
+	fmt.Fprintf(bw, "apiwatcher_checks_failed_total %d\n", failedChecks)
+	writeHeader(bw, "apiwatcher_alerts_sent_total", "counter", "Alerts sent since monitoring started.")
+	fmt.Fprintf(bw, "apiwatcher_alerts_sent_total %d\n", alertsSent)
+
+	// Per-website metrics, sorted for stable output
+	allStats := d.GetAllWebsiteStats()
+	urls := make([]string, 0, len(allStats))
+	for url := range allStats {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	writeHeader(bw, "apiwatcher_target_up", "gauge", "Whether the target's last check succeeded (1) or failed (0).")
+	for _, url := range urls {
+		stats := allStats[url]
+		if stats.TotalChecks == 0 {
+			continue
+		}
+		up := 1
+		if stats.ConsecutiveFailures > 0 {
+			up = 0
+		}
+		fmt.Fprintf(bw, "apiwatcher_target_up{target=%s} %d\n", quote(url), up)
+	}
+
+	writeHeader(bw, "apiwatcher_target_maintenance", "gauge", "Whether the target's last check ran inside a maintenance window.")
+	for _, url := range urls {
+		fmt.Fprintf(bw, "apiwatcher_target_maintenance{target=%s} %d\n", quote(url), boolToInt(allStats[url].InMaintenance))
+	}
+
+	writeHeader(bw, "apiwatcher_target_checks_total", "counter", "Checks run for the target.")
+	for _, url := range urls {
+		fmt.Fprintf(bw, "apiwatcher_target_checks_total{target=%s} %d\n", quote(url), allStats[url].TotalChecks)
+	}
+
+	writeHeader(bw, "apiwatcher_target_checks_failed_total", "counter", "Failed checks for the target.")
+	for _, url := range urls {
+		fmt.Fprintf(bw, "apiwatcher_target_checks_failed_total{target=%s} %d\n", quote(url), allStats[url].FailedChecks)
+	}
+
+	writeHeader(bw, "apiwatcher_target_alerts_sent_total", "counter", "Alerts sent for the target.")
+	for _, url := range urls {
+		fmt.Fprintf(bw, "apiwatcher_target_alerts_sent_total{target=%s} %d\n", quote(url), allStats[url].EmailsSent)
+	}
+
+	writeHeader(bw, "apiwatcher_target_uptime_ratio", "gauge", "Share of successful checks in the window (maintenance excluded).")
+	for _, url := range urls {
+		stats := allStats[url]
+		fmt.Fprintf(bw, "apiwatcher_target_uptime_ratio{target=%s,window=\"1h\"} %s\n", quote(url), formatFloat(stats.UptimeLastHour/100))
+		fmt.Fprintf(bw, "apiwatcher_target_uptime_ratio{target=%s,window=\"24h\"} %s\n", quote(url), formatFloat(stats.UptimeLast24Hours/100))
+		fmt.Fprintf(bw, "apiwatcher_target_uptime_ratio{target=%s,window=\"7d\"} %s\n", quote(url), formatFloat(stats.UptimeLast7Days/100))
+	}
+
+	writeHeader(bw, "apiwatcher_target_check_duration_seconds", "histogram", "Duration of checks for the target.")
+	for _, url := range urls {
+		stats := allStats[url]
+		if len(stats.DurationHistogram) != len(checkDurationBuckets)+1 {
+			continue
+		}
+		cumulative := 0
+		for i, bound := range checkDurationBuckets {
+			cumulative += stats.DurationHistogram[i]
+			fmt.Fprintf(bw, "apiwatcher_target_check_duration_seconds_bucket{target=%s,le=\"%s\"} %d\n", quote(url), formatFloat(bound), cumulative)
+		}
+		cumulative += stats.DurationHistogram[len(checkDurationBuckets)]
+		fmt.Fprintf(bw, "apiwatcher_target_check_duration_seconds_bucket{target=%s,le=\"+Inf\"} %d\n", quote(url), cumulative)
+		fmt.Fprintf(bw, "apiwatcher_target_check_duration_seconds_sum{target=%s} %s\n", quote(url), formatFloat(stats.DurationSum.Seconds()))
+		fmt.Fprintf(bw, "apiwatcher_target_check_duration_seconds_count{target=%s} %d\n", quote(url), cumulative)
+	}
+
+	writeHeader(bw, "apiwatcher_target_failed_requests_total", "counter", "Failed API calls seen while checking the target, by status code.")
+	for _, url := range urls {
+		byStatus := allStats[url].FailedRequestsByStatus
+		statuses := make([]int, 0, len(byStatus))
+		for status := range byStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(bw, "apiwatcher_target_failed_requests_total{target=%s,status=\"%d\"} %d\n", quote(url), status, byStatus[status])
+		}
+	}
+
+	writeHeader(bw, "apiwatcher_snapshot_replays_total", "counter", "Snapshot replays for the target, by outcome.")
+	for _, url := range urls {
+		outcomes := allStats[url].SnapshotOutcomes
+		for _, outcome := range []string{monitor.SnapshotPassed, monitor.SnapshotAPIErrors, monitor.SnapshotStepsFailed, monitor.SnapshotFailed} {
+			if count, ok := outcomes[outcome]; ok {
+				fmt.Fprintf(bw, "apiwatcher_snapshot_replays_total{target=%s,outcome=\"%s\"} %d\n", quote(url), outcome, count)
+			}
+		}
+	}
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// quote returns a quoted, escaped Prometheus label value
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ==========================
+// Metrics HTTP Server
+// ==========================
+
+// MetricsServer serves daemon metrics over HTTP for Prometheus to scrape
+type MetricsServer struct {
+	daemon  *Daemon
+	address string
+	server  *http.Server
+}
+
+// NewMetricsServer creates a metrics server listening on address (e.g. ":9877")
+func NewMetricsServer(daemon *Daemon, address string) *MetricsServer {
+	return &MetricsServer{daemon: daemon, address: address}
+}
+
+// Start starts serving /metrics
+func (m *MetricsServer) Start() error {
+	listener, err := net.Listen("tcp", m.address)
+	if err != nil {
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.daemon.WriteMetrics(w)
+	})
+
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("Metrics server listening on %s", listener.Addr())
+
+	go func() {
+		if err := m.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the metrics server
+func (m *MetricsServer) Stop() {
+	if m.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = m.server.Shutdown(ctx)
+	log.Println("Metrics server stopped")
+}
@@ -1,8 +1,15 @@
 package daemon
 
 import (
+	"apiwatcher/internal/alert"
+	"apiwatcher/internal/artifact"
 	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/history"
+	"apiwatcher/internal/notify"
+	"apiwatcher/internal/schedule"
 	"apiwatcher/internal/snapshot"
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -23,28 +30,52 @@ type Response struct {
 
 // Command types
 const (
-	CmdStatus          = "STATUS"
-	CmdStart           = "START"
-	CmdStop            = "STOP"
-	CmdPause           = "PAUSE"
-	CmdResume          = "RESUME"
-	CmdSetConfig       = "SET_CONFIG"
-	CmdGetConfig       = "GET_CONFIG"
-	CmdGetLogs         = "GET_LOGS"
-	CmdClearLogs       = "CLEAR_LOGS"
-	CmdGetStats        = "GET_STATS"
-	CmdGetWebsiteStats = "GET_WEBSITE_STATS"
-	CmdSetSMTP         = "SET_SMTP"
-	CmdGetSMTP         = "GET_SMTP"
-	CmdPing            = "PING"
-	CmdShutdown        = "SHUTDOWN"
+	CmdStatus            = "STATUS"
+	CmdStart             = "START"
+	CmdStop              = "STOP"
+	CmdPause             = "PAUSE"
+	CmdResume            = "RESUME"
+	CmdSetConfig         = "SET_CONFIG"
+	CmdGetConfig         = "GET_CONFIG"
+	CmdGetLogs           = "GET_LOGS"
+	CmdClearLogs         = "CLEAR_LOGS"
+	CmdGetStats          = "GET_STATS"
+	CmdGetWebsiteStats   = "GET_WEBSITE_STATS"
+	CmdSetSMTP           = "SET_SMTP"
+	CmdGetSMTP           = "GET_SMTP"
+	CmdSetWebhook        = "SET_WEBHOOK"
+	CmdGetWebhooks       = "GET_WEBHOOKS"
+	CmdDeleteWebhook     = "DELETE_WEBHOOK"
+	CmdTestWebhook       = "TEST_WEBHOOK"
+	CmdAckAlert          = "ACK_ALERT"
+	CmdListIncidents     = "LIST_INCIDENTS"
+	CmdSilence           = "SILENCE"
+	CmdUnsilence         = "UNSILENCE"
+	CmdListSilences      = "LIST_SILENCES"
+	CmdSetMaintenance    = "SET_MAINTENANCE"
+	CmdListMaintenance   = "LIST_MAINTENANCE"
+	CmdDeleteMaintenance = "DELETE_MAINTENANCE"
+	CmdGetHistory        = "GET_HISTORY"
+	CmdListHAR           = "LIST_HAR"
+	CmdGetHAR            = "GET_HAR"
+	CmdListArtifacts     = "LIST_ARTIFACTS"
+	CmdGetArtifact       = "GET_ARTIFACT"
+	CmdImportSnapshots   = "IMPORT_SNAPSHOTS"
+	CmdExportSnapshots   = "EXPORT_SNAPSHOTS"
+	CmdPing              = "PING"
+	CmdShutdown          = "SHUTDOWN"
 )
 
 // SetConfigPayload is the payload for SET_CONFIG command
 type SetConfigPayload struct {
 	Email       string            `json:"email"`
-	Websites    []string          `json:"websites"`
+	Websites    []config.Target   `json:"websites"` // Bare URL strings are accepted too
 	SnapshotIDs map[string]string `json:"snapshot_ids,omitempty"`
+
+	// Version 1 per-URL option maps sent by older clients, folded into Websites
+	HTTPProbes map[string]*config.HTTPProbe  `json:"http_probes,omitempty"`
+	Assertions map[string][]config.Assertion `json:"assertions,omitempty"`
+	Schedules  map[string]*schedule.Schedule `json:"schedules,omitempty"`
 }
 
 // GetLogsPayload is the payload for GET_LOGS command
@@ -62,6 +93,112 @@ type SetSMTPPayload struct {
 	To       string `json:"to"` // Email address to send alerts to
 }
 
+// WebhookNamePayload is the payload for DELETE_WEBHOOK and TEST_WEBHOOK commands
+type WebhookNamePayload struct {
+	Name string `json:"name"`
+}
+
+// WebhookInfo is a webhook returned by GET_WEBHOOKS (the secret is never sent back)
+type WebhookInfo struct {
+	config.WebhookConfig
+	HasSecret bool `json:"has_secret"`
+}
+
+// AckAlertPayload is the payload for ACK_ALERT command
+type AckAlertPayload struct {
+	Target string `json:"target"` // Website URL (or snapshot alert key)
+	By     string `json:"by,omitempty"`
+}
+
+// SilencePayload is the payload for SILENCE command
+type SilencePayload struct {
+	Target          string `json:"target"` // Website URL or "*" for every website
+	DurationMinutes int    `json:"duration_minutes"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// UnsilencePayload is the payload for UNSILENCE command
+type UnsilencePayload struct {
+	ID string `json:"id"`
+}
+
+// DeleteMaintenancePayload is the payload for DELETE_MAINTENANCE command
+type DeleteMaintenancePayload struct {
+	ID string `json:"id"`
+}
+
+// GetHistoryPayload is the payload for GET_HISTORY command
+type GetHistoryPayload struct {
+	Target     string    `json:"target"`
+	From       time.Time `json:"from"`                 // Zero = 24 hours before To
+	To         time.Time `json:"to"`                   // Zero = now
+	Resolution string    `json:"resolution,omitempty"` // Bucket size such as "5m" or "1h" (empty or "raw" = raw checks)
+}
+
+// HistoryData is the response data for GET_HISTORY command
+type HistoryData struct {
+	Target     string           `json:"target"`
+	From       time.Time        `json:"from"`
+	To         time.Time        `json:"to"`
+	Resolution string           `json:"resolution"`
+	Records    []history.Record `json:"records,omitempty"` // Raw checks
+	Buckets    []history.Bucket `json:"buckets,omitempty"` // Downsampled checks
+}
+
+// maxHistoryBuckets limits how many buckets a single GET_HISTORY may return
+const maxHistoryBuckets = 10000
+
+// ListHARPayload is the payload for LIST_HAR command
+type ListHARPayload struct {
+	Target string `json:"target,omitempty"` // Empty = every website
+}
+
+// GetHARPayload is the payload for GET_HAR command
+type GetHARPayload struct {
+	ID string `json:"id"`
+}
+
+// HARData is the response data for GET_HAR command
+type HARData struct {
+	Info     har.Info        `json:"info"`
+	FileName string          `json:"file_name"`
+	Content  json.RawMessage `json:"content"` // The HAR document
+}
+
+// ListArtifactsPayload is the payload for LIST_ARTIFACTS command
+type ListArtifactsPayload struct {
+	Target string `json:"target,omitempty"` // Empty = every website
+}
+
+// GetArtifactPayload is the payload for GET_ARTIFACT command
+type GetArtifactPayload struct {
+	ID string `json:"id"`
+}
+
+// ArtifactData is the response data for GET_ARTIFACT command
+type ArtifactData struct {
+	Info       artifact.Info `json:"info"`
+	Screenshot []byte        `json:"screenshot,omitempty"` // Full-page JPEG (base64 in JSON)
+	DOM        string        `json:"dom,omitempty"`        // Serialized DOM
+}
+
+// ImportSnapshotsPayload is the payload for IMPORT_SNAPSHOTS command
+type ImportSnapshotsPayload struct {
+	Format    string `json:"format"`              // snapshot.FormatBundle, FormatChromeRecorder or FormatScript
+	Data      []byte `json:"data"`                // File content (base64 in JSON)
+	Overwrite bool   `json:"overwrite,omitempty"` // Save bundled snapshots over existing ones with the same ID
+}
+
+// ExportSnapshotsPayload is the payload for EXPORT_SNAPSHOTS command
+type ExportSnapshotsPayload struct {
+	IDs []string `json:"ids,omitempty"` // Empty exports every snapshot
+}
+
+// ExportData is the response data for EXPORT_SNAPSHOTS command
+type ExportData struct {
+	Bundle []byte `json:"bundle"` // Zip bundle (base64 in JSON)
+}
+
 // StatusData is the response data for STATUS command
 type StatusData struct {
 	State        State     `json:"state"`
@@ -142,6 +279,63 @@ func (d *Daemon) HandleCommand(cmd Command) Response {
 	case CmdGetSMTP:
 		return d.handleGetSMTP()
 
+	case CmdSetWebhook:
+		return d.handleSetWebhook(cmd.Payload)
+
+	case CmdGetWebhooks:
+		return d.handleGetWebhooks()
+
+	case CmdDeleteWebhook:
+		return d.handleDeleteWebhook(cmd.Payload)
+
+	case CmdTestWebhook:
+		return d.handleTestWebhook(cmd.Payload)
+
+	case CmdAckAlert:
+		return d.handleAckAlert(cmd.Payload)
+
+	case CmdListIncidents:
+		return d.handleListIncidents()
+
+	case CmdSilence:
+		return d.handleSilence(cmd.Payload)
+
+	case CmdUnsilence:
+		return d.handleUnsilence(cmd.Payload)
+
+	case CmdListSilences:
+		return d.handleListSilences()
+
+	case CmdSetMaintenance:
+		return d.handleSetMaintenance(cmd.Payload)
+
+	case CmdListMaintenance:
+		return d.handleListMaintenance()
+
+	case CmdDeleteMaintenance:
+		return d.handleDeleteMaintenance(cmd.Payload)
+
+	case CmdGetHistory:
+		return d.handleGetHistory(cmd.Payload)
+
+	case CmdListHAR:
+		return d.handleListHAR(cmd.Payload)
+
+	case CmdGetHAR:
+		return d.handleGetHAR(cmd.Payload)
+
+	case CmdListArtifacts:
+		return d.handleListArtifacts(cmd.Payload)
+
+	case CmdGetArtifact:
+		return d.handleGetArtifact(cmd.Payload)
+
+	case CmdImportSnapshots:
+		return d.handleImportSnapshots(cmd.Payload)
+
+	case CmdExportSnapshots:
+		return d.handleExportSnapshots(cmd.Payload)
+
 	default:
 		return Response{
 			Success: false,
@@ -210,16 +404,27 @@ func (d *Daemon) handleSetConfig(payload json.RawMessage) Response {
 		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
 	}
 
-	// Create config
+	// Create config, folding in any per-URL options from older clients
 	cfg := &config.Config{
-		Email:    configPayload.Email,
-		Websites: configPayload.Websites,
+		Email:      configPayload.Email,
+		Websites:   configPayload.Websites,
+		HTTPProbes: configPayload.HTTPProbes,
+		Assertions: configPayload.Assertions,
+		Schedules:  configPayload.Schedules,
+	}
+	cfg.Migrate()
+
+	// Validate targets
+	for _, target := range cfg.Websites {
+		if err := config.ValidateTarget(target); err != nil {
+			return Response{Success: false, Message: fmt.Sprintf("invalid target %s: %v", target.URL, err)}
+		}
 	}
 
 	// Load ALL snapshots for each configured website
 	snapshots := make(map[string][]*snapshot.Snapshot)
 	snapshotCount := 0
-	for _, url := range configPayload.Websites {
+	for _, url := range cfg.URLs() {
 		// Load ALL snapshots for this URL from disk
 		snaps, err := snapshot.LoadForURL(url)
 		if err != nil {
@@ -368,3 +573,359 @@ func (d *Daemon) handleGetSMTP() Response {
 
 	return Response{Success: true, Data: response}
 }
+
+func (d *Daemon) handleSetWebhook(payload json.RawMessage) Response {
+	var webhook config.WebhookConfig
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	// Validate
+	if err := config.ValidateWebhookConfig(&webhook); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("validation error: %v", err)}
+	}
+
+	// Secrets are never sent back to clients, so an empty secret keeps the stored one
+	if webhook.Secret == "" {
+		if webhooks, err := config.LoadWebhooks(); err == nil {
+			for _, existing := range webhooks {
+				if existing.Name == webhook.Name {
+					webhook.Secret = existing.Secret
+					break
+				}
+			}
+		}
+	}
+
+	if err := config.SaveWebhook(&webhook); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to save webhook: %v", err)}
+	}
+
+	d.Logf("Webhook %s saved", webhook.Name)
+	return Response{Success: true, Message: "Webhook saved"}
+}
+
+func (d *Daemon) handleGetWebhooks() Response {
+	webhooks, err := config.LoadWebhooks()
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to load webhooks: %v", err)}
+	}
+
+	// Don't send secrets back for security
+	response := make([]WebhookInfo, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		info := WebhookInfo{WebhookConfig: *webhook, HasSecret: webhook.Secret != ""}
+		info.Secret = ""
+		response = append(response, info)
+	}
+
+	return Response{Success: true, Data: response}
+}
+
+func (d *Daemon) handleDeleteWebhook(payload json.RawMessage) Response {
+	var namePayload WebhookNamePayload
+	if err := json.Unmarshal(payload, &namePayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	if err := config.DeleteWebhook(namePayload.Name); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to delete webhook: %v", err)}
+	}
+
+	d.Logf("Webhook %s deleted", namePayload.Name)
+	return Response{Success: true, Message: "Webhook deleted"}
+}
+
+func (d *Daemon) handleTestWebhook(payload json.RawMessage) Response {
+	var namePayload WebhookNamePayload
+	if err := json.Unmarshal(payload, &namePayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	webhooks, err := config.LoadWebhooks()
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to load webhooks: %v", err)}
+	}
+
+	var webhook *config.WebhookConfig
+	for _, existing := range webhooks {
+		if existing.Name == namePayload.Name {
+			webhook = existing
+			break
+		}
+	}
+	if webhook == nil {
+		return Response{Success: false, Message: fmt.Sprintf("webhook not found: %s", namePayload.Name)}
+	}
+
+	testAlert := notify.Alert{
+		Key:       "test",
+		Kind:      notify.KindError,
+		Website:   "https://example.com",
+		Subject:   "🔔 ApiWatcher test alert",
+		Body:      "This is a test alert from ApiWatcher. If you can read this, the webhook works.",
+		Failures:  []notify.FailedRequest{{URL: "https://example.com/api/test", StatusCode: 500}},
+		Timestamp: time.Now(),
+	}
+	if err := notify.NewWebhookNotifier(webhook).Notify(context.Background(), testAlert); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("test delivery failed: %v", err)}
+	}
+
+	d.Logf("Test alert delivered to webhook %s", webhook.Name)
+	return Response{Success: true, Message: "Test alert delivered"}
+}
+
+func (d *Daemon) handleAckAlert(payload json.RawMessage) Response {
+	var ackPayload AckAlertPayload
+	if err := json.Unmarshal(payload, &ackPayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	incident, err := d.incidents.Acknowledge(ackPayload.Target, ackPayload.By)
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to acknowledge alert: %v", err)}
+	}
+
+	d.Logf("[ALERT] Incident for %s acknowledged", ackPayload.Target)
+	return Response{Success: true, Message: "Alert acknowledged", Data: incident}
+}
+
+func (d *Daemon) handleListIncidents() Response {
+	incidents, err := d.incidents.List()
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to load incidents: %v", err)}
+	}
+	return Response{Success: true, Data: incidents}
+}
+
+func (d *Daemon) handleSilence(payload json.RawMessage) Response {
+	var silencePayload SilencePayload
+	if err := json.Unmarshal(payload, &silencePayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.silences == nil {
+		return Response{Success: false, Message: "silences are not available"}
+	}
+
+	duration := time.Duration(silencePayload.DurationMinutes) * time.Minute
+	silence, err := d.silences.Add(silencePayload.Target, duration, silencePayload.Reason)
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to silence: %v", err)}
+	}
+
+	d.Logf("[ALERT] 🔕 Alerts for %s silenced until %s", silence.Target, formatTimeString(silence.ExpiresAt))
+	return Response{Success: true, Message: "Alerts silenced", Data: silence}
+}
+
+func (d *Daemon) handleUnsilence(payload json.RawMessage) Response {
+	var unsilencePayload UnsilencePayload
+	if err := json.Unmarshal(payload, &unsilencePayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.silences == nil {
+		return Response{Success: false, Message: "silences are not available"}
+	}
+
+	if err := d.silences.Remove(unsilencePayload.ID); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to remove silence: %v", err)}
+	}
+
+	d.Logf("[ALERT] 🔔 Silence %s removed", unsilencePayload.ID)
+	return Response{Success: true, Message: "Silence removed"}
+}
+
+func (d *Daemon) handleListSilences() Response {
+	if d.silences == nil {
+		return Response{Success: true, Data: []alert.Silence{}}
+	}
+	return Response{Success: true, Data: d.silences.List()}
+}
+
+func (d *Daemon) handleSetMaintenance(payload json.RawMessage) Response {
+	var window schedule.MaintenanceWindow
+	if err := json.Unmarshal(payload, &window); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.maintenance == nil {
+		return Response{Success: false, Message: "maintenance windows are not available"}
+	}
+
+	saved, err := d.maintenance.Save(window)
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to save maintenance window: %v", err)}
+	}
+
+	d.Logf("[MAINTENANCE] 🔧 Window %s saved (%s - %s)", saved.ID, formatTimeString(saved.Start), formatTimeString(saved.End))
+	return Response{Success: true, Message: "Maintenance window saved", Data: saved}
+}
+
+func (d *Daemon) handleListMaintenance() Response {
+	if d.maintenance == nil {
+		return Response{Success: true, Data: []schedule.MaintenanceWindow{}}
+	}
+	return Response{Success: true, Data: d.maintenance.List()}
+}
+
+func (d *Daemon) handleDeleteMaintenance(payload json.RawMessage) Response {
+	var deletePayload DeleteMaintenancePayload
+	if err := json.Unmarshal(payload, &deletePayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.maintenance == nil {
+		return Response{Success: false, Message: "maintenance windows are not available"}
+	}
+
+	if err := d.maintenance.Remove(deletePayload.ID); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to delete maintenance window: %v", err)}
+	}
+
+	d.Logf("[MAINTENANCE] Window %s deleted", deletePayload.ID)
+	return Response{Success: true, Message: "Maintenance window deleted"}
+}
+
+func (d *Daemon) handleGetHistory(payload json.RawMessage) Response {
+	var historyPayload GetHistoryPayload
+	if err := json.Unmarshal(payload, &historyPayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if historyPayload.Target == "" {
+		return Response{Success: false, Message: "target is required"}
+	}
+	if d.history == nil {
+		return Response{Success: false, Message: "check history is not available"}
+	}
+
+	to := historyPayload.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := historyPayload.From
+	if from.IsZero() {
+		from = to.Add(-24 * time.Hour)
+	}
+	if !to.After(from) {
+		return Response{Success: false, Message: "from must be before to"}
+	}
+
+	records, err := d.history.Query(historyPayload.Target, from, to)
+	if err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("failed to query history: %v", err)}
+	}
+
+	data := HistoryData{
+		Target:     historyPayload.Target,
+		From:       from,
+		To:         to,
+		Resolution: "raw",
+	}
+
+	if historyPayload.Resolution == "" || historyPayload.Resolution == "raw" {
+		data.Records = records
+		return Response{Success: true, Data: data}
+	}
+
+	resolution, err := time.ParseDuration(historyPayload.Resolution)
+	if err != nil || resolution <= 0 {
+		return Response{Success: false, Message: fmt.Sprintf("invalid resolution: %q", historyPayload.Resolution)}
+	}
+	if to.Sub(from)/resolution > maxHistoryBuckets {
+		return Response{Success: false, Message: fmt.Sprintf("resolution too fine: more than %d buckets", maxHistoryBuckets)}
+	}
+
+	data.Resolution = resolution.String()
+	data.Buckets = history.Downsample(records, from, to, resolution)
+	return Response{Success: true, Data: data}
+}
+
+func (d *Daemon) handleListHAR(payload json.RawMessage) Response {
+	var listPayload ListHARPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &listPayload); err != nil {
+			return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+		}
+	}
+	if d.harStore == nil {
+		return Response{Success: false, Message: "HAR recordings are not available"}
+	}
+
+	return Response{Success: true, Data: d.harStore.List(listPayload.Target)}
+}
+
+func (d *Daemon) handleGetHAR(payload json.RawMessage) Response {
+	var getPayload GetHARPayload
+	if err := json.Unmarshal(payload, &getPayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.harStore == nil {
+		return Response{Success: false, Message: "HAR recordings are not available"}
+	}
+
+	info, content, err := d.harStore.Read(getPayload.ID)
+	if err != nil {
+		return Response{Success: false, Message: err.Error()}
+	}
+	return Response{Success: true, Data: HARData{Info: *info, FileName: info.FileName(), Content: content}}
+}
+
+func (d *Daemon) handleListArtifacts(payload json.RawMessage) Response {
+	var listPayload ListArtifactsPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &listPayload); err != nil {
+			return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+		}
+	}
+	if d.artifacts == nil {
+		return Response{Success: false, Message: "failure artifacts are not available"}
+	}
+
+	return Response{Success: true, Data: d.artifacts.List(listPayload.Target)}
+}
+
+func (d *Daemon) handleGetArtifact(payload json.RawMessage) Response {
+	var getPayload GetArtifactPayload
+	if err := json.Unmarshal(payload, &getPayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+	if d.artifacts == nil {
+		return Response{Success: false, Message: "failure artifacts are not available"}
+	}
+
+	info, screenshot, dom, err := d.artifacts.Read(getPayload.ID)
+	if err != nil {
+		return Response{Success: false, Message: err.Error()}
+	}
+	return Response{Success: true, Data: ArtifactData{Info: *info, Screenshot: screenshot, DOM: dom}}
+}
+
+func (d *Daemon) handleImportSnapshots(payload json.RawMessage) Response {
+	var importPayload ImportSnapshotsPayload
+	if err := json.Unmarshal(payload, &importPayload); err != nil {
+		return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+	}
+
+	result, err := snapshot.Import(importPayload.Format, importPayload.Data, importPayload.Overwrite)
+	if err != nil {
+		return Response{Success: false, Message: err.Error()}
+	}
+	d.Logf("[SNAPSHOT] 📥 Imported %d snapshot(s) (%s)", len(result.IDs), importPayload.Format)
+	return Response{Success: true, Data: result}
+}
+
+func (d *Daemon) handleExportSnapshots(payload json.RawMessage) Response {
+	var exportPayload ExportSnapshotsPayload
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &exportPayload); err != nil {
+			return Response{Success: false, Message: fmt.Sprintf("invalid payload: %v", err)}
+		}
+	}
+
+	snaps, err := snapshot.LoadByIDs(exportPayload.IDs)
+	if err != nil {
+		return Response{Success: false, Message: err.Error()}
+	}
+	bundle, err := snapshot.ExportBundle(snaps)
+	if err != nil {
+		return Response{Success: false, Message: err.Error()}
+	}
+	return Response{Success: true, Data: ExportData{Bundle: bundle}}
+}
@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/monitor"
+	"apiwatcher/internal/snapshot"
+)
+
+// scheduleWebsite dispatches check jobs for a single website according to its own schedule
+// until the context is cancelled. The first check runs immediately.
+func (d *Daemon) scheduleWebsite(ctx context.Context, target config.Target, jobQueue chan<- monitor.Job) {
+	site := target.URL
+	sched := target.Schedule
+	d.Logf("[SCHEDULER] %s (%s check): %s", target.DisplayName(), target.Mode(), sched.Describe(defaultCheckInterval()))
+
+	next := time.Now()
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		// Don't pile up checks if the previous one is still queued or running
+		if d.markInFlight(site) {
+			job := monitor.Job{APIJob: monitor.APIJob{
+				Website:             site,
+				Email:               d.targetAlertEmail(target),
+				Probe:               target.Probe(), // nil falls back to the browser check
+				Assertions:          target.Assertions,
+				Wait:                target.Wait,
+				Timeout:             target.Timeout(),
+				Policy:              target.AlertPolicy,
+				ConsecutiveFailures: d.consecutiveFailures(site),
+				Silences:            d.silences,
+				Incidents:           d.incidents,
+			}}
+
+			d.jobWaitGroup.Add(1)
+			select {
+			case jobQueue <- job:
+			case <-ctx.Done():
+				d.finishJob(site)
+				return
+			}
+		} else {
+			d.Logf("[SCHEDULER] Previous check for %s still running, skipping this run", site)
+		}
+
+		// Reload settings on each run to pick up any changes to the default interval
+		_ = config.LoadSettings()
+		next = sched.Next(time.Now(), defaultCheckInterval())
+	}
+}
+
+// snapshotRunner replays queued websites' snapshots one at a time
+func (d *Daemon) snapshotRunner(snapshotQueue <-chan string) {
+	for site := range snapshotQueue {
+		if d.monitoringActive {
+			snapJob := monitor.SnapshotJob{
+				Website:     site,
+				Email:       d.alertEmail(),
+				Snapshots:   d.getSnapshots(site),
+				Policy:      d.alertPolicyFor(site),
+				Silences:    d.silences,
+				Incidents:   d.incidents,
+				Maintenance: d.inMaintenance(site),
+				RecordHAR:   d.harMode(site) != config.HARNever,
+			}
+			runs := monitor.ProcessSnapshots(snapJob, d)
+			d.recordSnapshotRuns(site, runs)
+			d.saveReplayHARs(site, runs)
+			d.saveReplayArtifacts(site, runs)
+		}
+		d.clearInFlight("snapshot:" + site)
+	}
+}
+
+// defaultCheckInterval is used for websites without their own schedule
+func defaultCheckInterval() time.Duration {
+	return time.Duration(config.GetWorkerSleepTime()) * time.Minute
+}
+
+// alertEmail returns the address alerts should be sent to
+func (d *Daemon) alertEmail() string {
+	// Load SMTP config to get alert email
+	smtpConfig, _ := config.LoadSMTPConfig()
+	if smtpConfig != nil && smtpConfig.To != "" {
+		return smtpConfig.To
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if d.config == nil {
+		return ""
+	}
+	return d.config.Email // Fallback to config email
+}
+
+// targetAlertEmail returns the alert recipients for a target, honouring its alert routing
+func (d *Daemon) targetAlertEmail(target config.Target) string {
+	if len(target.AlertEmails) > 0 {
+		return strings.Join(target.AlertEmails, ",")
+	}
+	return d.alertEmail()
+}
+
+// alertEmailFor returns the alert recipients for a monitored URL
+func (d *Daemon) alertEmailFor(url string) string {
+	d.mutex.RLock()
+	var target config.Target
+	found := false
+	if t := d.config.Target(url); t != nil {
+		target, found = *t, true
+	}
+	d.mutex.RUnlock()
+
+	if !found {
+		return d.alertEmail()
+	}
+	return d.targetAlertEmail(target)
+}
+
+// alertPolicyFor returns the alert policy for a monitored URL (nil = default policy)
+func (d *Daemon) alertPolicyFor(url string) *config.AlertPolicy {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if t := d.config.Target(url); t != nil {
+		return t.AlertPolicy
+	}
+	return nil
+}
+
+// consecutiveFailures returns the current failure streak of a website
+func (d *Daemon) consecutiveFailures(url string) int {
+	stats := d.GetWebsiteStats(url)
+	if stats == nil {
+		return 0
+	}
+	stats.mutex.RLock()
+	defer stats.mutex.RUnlock()
+	return stats.ConsecutiveFailures
+}
+
+// inMaintenance reports whether a website is inside a maintenance window right now
+func (d *Daemon) inMaintenance(url string) bool {
+	return d.maintenance.Active(url, time.Now()) != nil
+}
+
+// getSnapshots returns the snapshots configured for a website
+func (d *Daemon) getSnapshots(site string) []*snapshot.Snapshot {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.snapshotsByURL[site]
+}
+
+// markInFlight marks a key as in flight, returning false if it already was
+func (d *Daemon) markInFlight(key string) bool {
+	d.inFlightMutex.Lock()
+	defer d.inFlightMutex.Unlock()
+	if d.inFlight[key] {
+		return false
+	}
+	d.inFlight[key] = true
+	return true
+}
+
+// clearInFlight clears the in-flight mark for a key
+func (d *Daemon) clearInFlight(key string) {
+	d.inFlightMutex.Lock()
+	defer d.inFlightMutex.Unlock()
+	delete(d.inFlight, key)
+}
+
+// finishJob marks a queued check job as done
+func (d *Daemon) finishJob(site string) {
+	d.clearInFlight(site)
+	d.jobWaitGroup.Done()
+}
@@ -9,6 +9,9 @@ import (
 	"sync"
 )
 
+// maxCommandSize bounds a command line, large enough for imported snapshot bundles
+const maxCommandSize = 32 << 20
+
 // Server handles incoming control connections
 type Server struct {
 	daemon   *Daemon
@@ -88,6 +91,7 @@ func (s *Server) handleConnection(conn net.Conn) {
 	log.Printf("Client connected: %s", conn.RemoteAddr())
 
 	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCommandSize)
 	encoder := json.NewEncoder(conn)
 
 	for scanner.Scan() {
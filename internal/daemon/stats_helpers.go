@@ -1,14 +1,21 @@
 package daemon
 
 import (
+	"apiwatcher/internal/monitor"
+	"context"
 	"time"
 )
 
 // UpdateWebsiteStats updates statistics after a check
-func (d *Daemon) UpdateWebsiteStats(url string, success bool, duration time.Duration, alertSent bool) {
+// Checks inside a maintenance window are recorded but don't count as up or down
+func (d *Daemon) UpdateWebsiteStats(ctx context.Context, url string, success bool, duration time.Duration, alertSent bool, maintenance bool) {
+	// Resolved before locking the stats: alertEmailFor takes d.mutex, which is
+	// held while the stats are read when the state is saved
+	email := d.alertEmailFor(url)
+	var resolved *resolvedOutage
+
 	stats := d.GetOrCreateWebsiteStats(url)
 	stats.mutex.Lock()
-	defer stats.mutex.Unlock()
 
 	// Update basic counters
 	stats.TotalChecks++
@@ -16,15 +23,16 @@ func (d *Daemon) UpdateWebsiteStats(url string, success bool, duration time.Dura
 
 	// Record check in history
 	checkRecord := CheckRecord{
-		Timestamp: time.Now(),
-		Success:   success,
-		Duration:  duration,
+		Timestamp:   time.Now(),
+		Success:     success,
+		Duration:    duration,
+		Maintenance: maintenance,
 	}
 	stats.CheckHistory = append(stats.CheckHistory, checkRecord)
 
-	// Keep only last 7 days of history (assuming ~5 min intervals = ~2000 checks)
-	if len(stats.CheckHistory) > 2000 {
-		stats.CheckHistory = stats.CheckHistory[len(stats.CheckHistory)-2000:]
+	// Keep only the most recent checks in memory; the full history lives in the history store
+	if len(stats.CheckHistory) > checkHistoryLimit {
+		stats.CheckHistory = stats.CheckHistory[len(stats.CheckHistory)-checkHistoryLimit:]
 	}
 
 	// Update response times ring buffer
@@ -36,7 +44,12 @@ func (d *Daemon) UpdateWebsiteStats(url string, success bool, duration time.Dura
 		stats.AverageResponseTime = calculateAverageResponseTime(stats.ResponseTimes)
 	}
 
-	if success {
+	stats.InMaintenance = maintenance
+
+	switch {
+	case maintenance:
+		// Neither up nor down: leave failure streaks and downtime tracking untouched
+	case success:
 		stats.ConsecutiveSuccesses++
 		stats.ConsecutiveFailures = 0
 		stats.LastSuccessTime = time.Now()
@@ -50,8 +63,13 @@ func (d *Daemon) UpdateWebsiteStats(url string, success bool, duration time.Dura
 			if stats.LastDowntimeDuration > stats.LongestDowntime {
 				stats.LongestDowntime = stats.LastDowntimeDuration
 			}
+
+			// Tell everyone who was alerted about this outage that it is over
+			if !stats.LastAlertSent.IsZero() && !stats.LastAlertSent.Before(stats.LastDowntimeStart) {
+				resolved = &resolvedOutage{start: stats.LastDowntimeStart, lastFailure: stats.LastFailureTime, duration: stats.LastDowntimeDuration}
+			}
 		}
-	} else {
+	default:
 		stats.FailedChecks++
 		stats.ConsecutiveFailures++
 		stats.ConsecutiveSuccesses = 0
@@ -73,10 +91,25 @@ func (d *Daemon) UpdateWebsiteStats(url string, success bool, duration time.Dura
 	stats.OverallHealthPercent = calculateHealthPercentage(stats.TotalChecks, stats.FailedChecks)
 	stats.UptimeLastHour = calculateUptimePercentage(stats.CheckHistory, 1*time.Hour)
 	stats.UptimeLast24Hours = calculateUptimePercentage(stats.CheckHistory, 24*time.Hour)
-	stats.UptimeLast7Days = calculateUptimePercentage(stats.CheckHistory, 7*24*time.Hour)
+	if d.history == nil {
+		// The in-memory history may not cover the week, see refreshWeeklyUptime
+		stats.UptimeLast7Days = calculateUptimePercentage(stats.CheckHistory, 7*24*time.Hour)
+	}
 
 	// Calculate health trend
 	stats.HealthTrend = calculateHealthTrend(stats.CheckHistory)
+	stats.mutex.Unlock()
+
+	if resolved != nil {
+		monitor.SendResolvedAlert(ctx, url, email, resolved.start, resolved.lastFailure, resolved.duration, d)
+	}
+}
+
+// resolvedOutage is an outage that ended, copied out of the stats to alert about it
+type resolvedOutage struct {
+	start       time.Time
+	lastFailure time.Time
+	duration    time.Duration
 }
 
 // calculateHealthPercentage calculates overall health percentage
@@ -101,6 +134,9 @@ func calculateUptimePercentage(history []CheckRecord, duration time.Duration) fl
 		if history[i].Timestamp.Before(cutoffTime) {
 			break
 		}
+		if history[i].Maintenance {
+			continue
+		}
 		totalChecks++
 		if history[i].Success {
 			successfulChecks++
@@ -116,6 +152,15 @@ func calculateUptimePercentage(history []CheckRecord, duration time.Duration) fl
 
 // calculateHealthTrend determines if health is improving, stable, or degrading
 func calculateHealthTrend(history []CheckRecord) string {
+	// Maintenance results say nothing about health
+	counted := make([]CheckRecord, 0, len(history))
+	for _, check := range history {
+		if !check.Maintenance {
+			counted = append(counted, check)
+		}
+	}
+	history = counted
+
 	if len(history) < 10 {
 		return "stable"
 	}
@@ -178,7 +223,9 @@ func (ws *WebsiteStats) GetCurrentStatus() string {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
 
-	if ws.ConsecutiveFailures > 0 {
+	if ws.InMaintenance {
+		return "Maintenance"
+	} else if ws.ConsecutiveFailures > 0 {
 		return "Down"
 	} else if ws.ConsecutiveSuccesses > 0 {
 		return "Up"
@@ -4,6 +4,7 @@ import (
 	"apiwatcher/internal/config"
 	"fmt"
 	"net/smtp"
+	"strings"
 )
 
 // ==========================
@@ -25,7 +26,7 @@ func Send(to, subject, body string) error {
 		body + "\r\n")
 
 	auth := smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
-	return smtp.SendMail(addr, auth, smtpConfig.From, []string{to}, msg)
+	return smtp.SendMail(addr, auth, smtpConfig.From, recipients(to), msg)
 }
 
 // sendWithEnvVars sends email using environment variables (legacy support)
@@ -41,5 +42,16 @@ func sendWithEnvVars(to, subject, body string) error {
 		body + "\r\n")
 
 	auth := smtp.PlainAuth("", config.SMTPUser, config.SMTPPass, config.SMTPHost)
-	return smtp.SendMail(addr, auth, config.SMTPFrom, []string{to}, msg)
+	return smtp.SendMail(addr, auth, config.SMTPFrom, recipients(to), msg)
+}
+
+// recipients splits a comma separated address list (used for per-target alert routing)
+func recipients(to string) []string {
+	var list []string
+	for _, addr := range strings.Split(to, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, addr)
+		}
+	}
+	return list
 }
@@ -0,0 +1,379 @@
+package har
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"apiwatcher/internal/models"
+)
+
+// Version is the HAR format version written
+const Version = "1.2"
+
+// HAR is an HTTP Archive (http://www.softwareishard.com/blog/har-12-spec/)
+// that browsers' devtools can import
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Page struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	ID              string      `json:"id"`
+	Title           string      `json:"title"`
+	PageTimings     PageTimings `json:"pageTimings"`
+}
+
+// PageTimings are unknown (-1): the session may span several pages
+type PageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type Entry struct {
+	Pageref         string   `json:"pageref,omitempty"`
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"` // Total milliseconds
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ResourceType    string   `json:"_resourceType,omitempty"`
+	Error           string   `json:"_error,omitempty"` // Network error of a request without a response
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type Response struct {
+	Status       int         `json:"status"`
+	StatusText   string      `json:"statusText"`
+	HTTPVersion  string      `json:"httpVersion"`
+	Cookies      []Cookie    `json:"cookies"`
+	Headers      []NameValue `json:"headers"`
+	Content      Content     `json:"content"`
+	RedirectURL  string      `json:"redirectURL"`
+	HeadersSize  int         `json:"headersSize"`
+	BodySize     int64       `json:"bodySize"`
+	TransferSize int64       `json:"_transferSize,omitempty"` // Bytes on the wire, headers included
+}
+
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"` // Only captured for text responses
+}
+
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Params   []NameValue `json:"params"`
+	Text     string      `json:"text"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings are in milliseconds; -1 marks phases that don't apply
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// ==========================
+// Building
+// ==========================
+
+// Build creates a HAR with a single page for a browser session or probe.
+// requests are the captured requests in the order they were sent.
+func Build(title string, startedAt time.Time, requests []*models.APIRequest) *HAR {
+	const pageID = "page_1"
+
+	h := &HAR{Log: Log{
+		Version: Version,
+		Creator: Creator{Name: "apiwatcher", Version: "1.0.0"},
+		Pages: []Page{{
+			StartedDateTime: formatTime(startedAt),
+			ID:              pageID,
+			Title:           title,
+			PageTimings:     PageTimings{OnContentLoad: -1, OnLoad: -1},
+		}},
+		Entries: make([]Entry, 0, len(requests)),
+	}}
+
+	for _, r := range requests {
+		entry := newEntry(r)
+		entry.Pageref = pageID
+		h.Log.Entries = append(h.Log.Entries, entry)
+	}
+	return h
+}
+
+func newEntry(r *models.APIRequest) Entry {
+	started := r.StartedAt
+	if started.IsZero() {
+		started = r.Timestamp
+	}
+
+	entry := Entry{
+		StartedDateTime: formatTime(started),
+		Request: Request{
+			Method:      r.Method,
+			URL:         redactURL(r.URL),
+			HTTPVersion: r.Protocol,
+			Cookies:     []Cookie{},
+			Headers:     nameValues(r.ReqHeaders),
+			QueryString: queryString(r.URL),
+			HeadersSize: -1,
+			BodySize:    r.RequestSize,
+		},
+		Response: Response{
+			Status:      r.StatusCode,
+			StatusText:  r.StatusText,
+			HTTPVersion: r.Protocol,
+			Cookies:     []Cookie{},
+			Headers:     nameValues(r.RespHeaders),
+			Content: Content{
+				Size:     r.BodySize,
+				MimeType: r.MIMEType,
+			},
+			RedirectURL:  redactURL(headerValue(r.RespHeaders, "Location")),
+			HeadersSize:  -1,
+			BodySize:     -1,
+			TransferSize: r.TransferSize,
+		},
+		Timings:      Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+		ResourceType: r.ResourceType,
+		Error:        r.Error,
+	}
+
+	if r.Method == "" {
+		entry.Request.Method = "GET"
+	}
+	if r.RequestBody != "" || r.RequestSize > 0 {
+		entry.Request.PostData = &PostData{
+			MimeType: headerValue(r.ReqHeaders, "Content-Type"),
+			Params:   []NameValue{},
+			Text:     r.RequestBody,
+		}
+	}
+	if r.Error == "" && r.Body != "" {
+		entry.Response.Content.Text = r.Body
+		if setsCredentials(r) {
+			entry.Response.Content.Text = redactedValue
+		}
+	}
+
+	if t := r.Timing; t != nil {
+		entry.Time = t.Total
+		entry.Timings = Timings{
+			Blocked: t.Blocked,
+			DNS:     t.DNS,
+			Connect: t.Connect,
+			Send:    t.Send,
+			Wait:    t.Wait,
+			Receive: t.Receive,
+			SSL:     t.TLS,
+		}
+	}
+	return entry
+}
+
+// formatTime formats a time as ISO 8601 with milliseconds, as HAR requires
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// redactedHeaders carry credentials and are never written to a HAR file
+var redactedHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// redactedParams are query parameters that carry credentials (lower case)
+var redactedParams = map[string]bool{
+	"token":                true,
+	"access_token":         true,
+	"id_token":             true,
+	"refresh_token":        true,
+	"code":                 true,
+	"key":                  true,
+	"api_key":              true,
+	"apikey":               true,
+	"sig":                  true,
+	"signature":            true,
+	"secret":               true,
+	"client_secret":        true,
+	"password":             true,
+	"x-amz-credential":     true,
+	"x-amz-signature":      true,
+	"x-amz-security-token": true,
+}
+
+// sessionCookieNames are the parts of cookie names that mark session or auth cookies
+var sessionCookieNames = []string{"session", "sess", "sid", "auth", "token", "jwt", "login"}
+
+// tokenFields are the JSON fields of token responses (OAuth and the like)
+var tokenFields = []string{`"access_token"`, `"id_token"`, `"refresh_token"`, `"token"`}
+
+// redactedValue replaces the values of redacted headers, parameters and dropped bodies
+const redactedValue = "[redacted]"
+
+// DropBodies removes the request and response bodies of the requests started at or
+// after since, e.g. the form posts and their answers that follow typing a password
+// during a replay
+func DropBodies(requests []*models.APIRequest, since time.Time) {
+	for _, r := range requests {
+		started := r.StartedAt
+		if started.IsZero() {
+			started = r.Timestamp
+		}
+		if started.Before(since) {
+			continue
+		}
+		if r.RequestBody != "" {
+			r.RequestBody = redactedValue
+		}
+		if r.Body != "" {
+			r.Body = redactedValue
+		}
+	}
+}
+
+// setsCredentials reports whether a response hands out credentials: a session or
+// auth cookie, or a token in its body
+func setsCredentials(r *models.APIRequest) bool {
+	for _, cookie := range strings.Split(headerValue(r.RespHeaders, "Set-Cookie"), "\n") {
+		name, _, _ := strings.Cut(cookie, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, part := range sessionCookieNames {
+			if name != "" && strings.Contains(name, part) {
+				return true
+			}
+		}
+	}
+	body := strings.ToLower(r.Body)
+	for _, field := range tokenFields {
+		if strings.Contains(body, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL replaces the values of credential query parameters in a URL, and in a
+// fragment shaped like a query (e.g. #access_token=...), keeping everything else as is
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.RawQuery = redactQuery(u.RawQuery)
+	if strings.Contains(u.Fragment, "=") {
+		u.RawFragment = redactQuery(u.EscapedFragment())
+		u.Fragment, _ = url.PathUnescape(u.RawFragment)
+	}
+	return u.String()
+}
+
+// redactQuery redacts the credential parameters of a raw query, keeping their order
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if !hasValue {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil && redactedParams[strings.ToLower(unescaped)] {
+			params[i] = name + "=" + url.QueryEscape(redactedValue)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// nameValues converts headers into HAR name/value pairs sorted by name
+func nameValues(headers map[string]string) []NameValue {
+	pairs := make([]NameValue, 0, len(headers))
+	for name, value := range headers {
+		if redactedHeaders[strings.ToLower(name)] {
+			pairs = append(pairs, NameValue{Name: name, Value: redactedValue})
+			continue
+		}
+		if strings.EqualFold(name, "Location") {
+			value = redactURL(value)
+		}
+		// CDP joins repeated headers with newlines
+		for _, v := range strings.Split(value, "\n") {
+			pairs = append(pairs, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+func queryString(rawURL string) []NameValue {
+	pairs := []NameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return pairs
+	}
+	for name, values := range u.Query() {
+		for _, v := range values {
+			if redactedParams[strings.ToLower(name)] {
+				v = redactedValue
+			}
+			pairs = append(pairs, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs
+}
+
+// headerValue looks a header up case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+package har
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Recording kinds
+const (
+	KindCheck  = "check"  // A website check
+	KindReplay = "replay" // A snapshot replay
+)
+
+// maxPerTarget is how many recordings are kept per website; older ones are deleted
+const maxPerTarget = 20
+
+const indexFile = "index.json"
+
+// Info describes a stored HAR recording
+type Info struct {
+	ID         string    `json:"id"`
+	Target     string    `json:"target"`
+	Kind       string    `json:"kind"`                       // KindCheck or KindReplay
+	SnapshotID string    `json:"snapshot_id,omitempty"`      // Replayed snapshot (KindReplay)
+	Version    int       `json:"snapshot_version,omitempty"` // Replayed snapshot version
+	CreatedAt  time.Time `json:"created_at"`
+	Success    bool      `json:"success"`
+	Entries    int       `json:"entries"`
+	Size       int64     `json:"size"` // File size in bytes
+}
+
+// FileName returns a download file name for the recording
+func (i *Info) FileName() string {
+	name := i.Kind
+	if i.SnapshotID != "" {
+		name += "-" + i.SnapshotID
+	}
+	return fmt.Sprintf("%s-%s.har", name, i.CreatedAt.UTC().Format("20060102-150405"))
+}
+
+// Store keeps HAR recordings in a directory with a JSON index
+type Store struct {
+	dir   string
+	index []*Info
+	mutex sync.RWMutex
+}
+
+// OpenStore opens (creating if needed) a HAR store rooted at dir
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create HAR directory: %w", err)
+	}
+	store := &Store{dir: dir, index: []*Info{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read HAR index: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.index); err != nil {
+		return store, fmt.Errorf("failed to unmarshal HAR index: %w", err)
+	}
+	return store, nil
+}
+
+// Save stores a recording and deletes the target's oldest ones beyond the limit
+func (s *Store) Save(info Info, h *HAR) (*Info, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HAR: %w", err)
+	}
+
+	info.ID = newRecordingID()
+	if info.CreatedAt.IsZero() {
+		info.CreatedAt = time.Now()
+	}
+	info.Entries = len(h.Log.Entries)
+	info.Size = int64(len(data))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.WriteFile(s.path(info.ID), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write HAR: %w", err)
+	}
+	s.index = append(s.index, &info)
+	s.pruneLocked(info.Target)
+	return &info, s.saveLocked()
+}
+
+// List returns the recordings of a target (every target if empty), newest first
+func (s *Store) List(target string) []Info {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := []Info{}
+	for _, info := range s.index {
+		if target == "" || info.Target == target {
+			list = append(list, *info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Read returns a recording and its HAR file contents
+func (s *Store) Read(id string) (*Info, []byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, info := range s.index {
+		if info.ID == id {
+			data, err := os.ReadFile(s.path(id))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read HAR: %w", err)
+			}
+			found := *info
+			return &found, data, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("HAR recording not found: %s", id)
+}
+
+// pruneLocked deletes a target's oldest recordings beyond maxPerTarget. Caller must hold the write lock.
+func (s *Store) pruneLocked(target string) {
+	sort.SliceStable(s.index, func(i, j int) bool { return s.index[i].CreatedAt.Before(s.index[j].CreatedAt) })
+
+	count := 0
+	for _, info := range s.index {
+		if info.Target == target {
+			count++
+		}
+	}
+
+	kept := s.index[:0]
+	for _, info := range s.index {
+		if info.Target == target && count > maxPerTarget {
+			os.Remove(s.path(info.ID))
+			count--
+			continue
+		}
+		kept = append(kept, info)
+	}
+	s.index = kept
+}
+
+// saveLocked writes the index to disk. Caller must hold the write lock.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal HAR index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, indexFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write HAR index: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".har")
+}
+
+func newRecordingID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Bucket summarises the checks of one time slot
+type Bucket struct {
+	Start        time.Time `json:"start"`
+	Checks       int       `json:"checks"`
+	Failures     int       `json:"failures"`
+	Maintenance  int       `json:"maintenance"`   // Checks inside a maintenance window (excluded from the ratio)
+	SuccessRatio *float64  `json:"success_ratio"` // 0-1 (1 when every check was maintenance, null without checks)
+	Errors       int       `json:"errors"`        // Failed API calls and assertions
+	P50Ms        int64     `json:"p50_ms"`
+	P95Ms        int64     `json:"p95_ms"`
+	P99Ms        int64     `json:"p99_ms"`
+}
+
+// Downsample groups records into buckets of the given resolution starting at from.
+// Empty slots are included so charts get an evenly spaced series.
+func Downsample(records []Record, from, to time.Time, resolution time.Duration) []Bucket {
+	if resolution <= 0 || !to.After(from) {
+		return []Bucket{}
+	}
+
+	count := int(to.Sub(from) / resolution)
+	if to.Sub(from)%resolution != 0 {
+		count++
+	}
+
+	buckets := make([]Bucket, count)
+	durations := make([][]time.Duration, count)
+	successes := make([]int, count)
+	for i := range buckets {
+		buckets[i].Start = from.Add(time.Duration(i) * resolution)
+	}
+
+	for _, r := range records {
+		if r.Timestamp.Before(from) || !r.Timestamp.Before(to) {
+			continue
+		}
+		i := int(r.Timestamp.Sub(from) / resolution)
+		b := &buckets[i]
+		b.Checks++
+		b.Errors += r.ErrorCount
+		if r.Duration > 0 {
+			durations[i] = append(durations[i], r.Duration)
+		}
+		switch {
+		case r.Maintenance:
+			b.Maintenance++
+		case r.Success:
+			successes[i]++
+		default:
+			b.Failures++
+		}
+	}
+
+	for i := range buckets {
+		b := &buckets[i]
+		switch counted := b.Checks - b.Maintenance; {
+		case counted > 0:
+			ratio := float64(successes[i]) / float64(counted)
+			b.SuccessRatio = &ratio
+		case b.Checks > 0:
+			// Nothing failed that counts, the slot isn't a gap either
+			ratio := 1.0
+			b.SuccessRatio = &ratio
+		}
+
+		sort.Slice(durations[i], func(x, y int) bool { return durations[i][x] < durations[i][y] })
+		b.P50Ms = percentile(durations[i], 0.50).Milliseconds()
+		b.P95Ms = percentile(durations[i], 0.95).Milliseconds()
+		b.P99Ms = percentile(durations[i], 0.99).Milliseconds()
+	}
+
+	return buckets
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,430 @@
+package history
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"apiwatcher/internal/models"
+)
+
+// DefaultRetention is how long check history is kept unless configured otherwise
+const DefaultRetention = 90 * 24 * time.Hour
+
+const (
+	segmentDateFormat = "2006-01-02"
+	segmentExt        = ".jsonl"    // Open (today's) segment, appended to
+	compactedExt      = ".jsonl.gz" // Closed segment, sorted and compressed
+	targetFile        = "target"    // Holds the target URL of a history directory
+)
+
+// Record is a single check result
+type Record struct {
+	Timestamp   time.Time     `json:"t"`
+	Success     bool          `json:"ok"`
+	Duration    time.Duration `json:"d"`
+	Maintenance bool          `json:"m,omitempty"`      // Ran inside a maintenance window
+	ErrorCount  int           `json:"errors,omitempty"` // Failed API calls and assertions
+	Error       string        `json:"error,omitempty"`  // Check error (e.g. timeout)
+	Requests    []Request     `json:"requests,omitempty"`
+}
+
+// Request is an API call captured during a check. Headers are only kept for
+// failed calls so the history stays small.
+type Request struct {
+	URL          string                `json:"url"`
+	Method       string                `json:"method,omitempty"`
+	Status       int                   `json:"status"`
+	Type         string                `json:"type,omitempty"` // Resource type (Document, XHR, Fetch, ...)
+	StartedAt    time.Time             `json:"started_at,omitzero"`
+	Timing       *models.RequestTiming `json:"timing,omitempty"`
+	RequestSize  int64                 `json:"req_size,omitempty"`
+	TransferSize int64                 `json:"transfer_size,omitempty"`
+	BodySize     int64                 `json:"body_size,omitempty"`
+	ReqHeaders   map[string]string     `json:"req_headers,omitempty"`
+	RespHeaders  map[string]string     `json:"resp_headers,omitempty"`
+	Error        string                `json:"error,omitempty"`
+}
+
+// NewRequest converts a captured request for storage
+func NewRequest(r *models.APIRequest) Request {
+	req := Request{
+		URL:          r.URL,
+		Method:       r.Method,
+		Status:       r.StatusCode,
+		Type:         r.ResourceType,
+		StartedAt:    r.StartedAt,
+		Timing:       r.Timing,
+		RequestSize:  r.RequestSize,
+		TransferSize: r.TransferSize,
+		BodySize:     r.BodySize,
+		Error:        r.Error,
+	}
+	if r.StatusCode >= 400 || r.StatusCode == 0 {
+		req.ReqHeaders = r.ReqHeaders
+		req.RespHeaders = r.RespHeaders
+	}
+	return req
+}
+
+// Store is an append-only, per-target check history.
+//
+// Each target gets its own directory holding one segment file per UTC day.
+// Today's segment is plain JSON lines and only ever appended to; once a day
+// is over its segment is compacted (sorted and gzipped). Segments older than
+// the retention period are deleted.
+type Store struct {
+	dir       string
+	retention time.Duration
+	mutex     sync.Mutex
+	lastDay   map[string]string // Target directory -> day of the last append
+}
+
+// Open opens (creating if needed) a history store rooted at dir
+func Open(dir string, retention time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	s := &Store{dir: dir, retention: retention, lastDay: make(map[string]string)}
+	if err := s.Maintain(time.Now()); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// SetRetention changes how long history is kept
+func (s *Store) SetRetention(retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.retention = retention
+}
+
+// Append adds a record to the target's history
+func (s *Store) Append(target string, r Record) error {
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir, err := s.targetDir(target, true)
+	if err != nil {
+		return err
+	}
+
+	// A new day closes the previous segments, so compact and prune them
+	day := r.Timestamp.UTC().Format(segmentDateFormat)
+	if s.lastDay[dir] != day {
+		s.lastDay[dir] = day
+		if err := s.maintainDir(dir, r.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, day+segmentExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history segment: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append history record: %w", err)
+	}
+	return nil
+}
+
+// Query returns the target's records with from <= timestamp < to, oldest first.
+// A zero from or to leaves that end of the range open.
+func (s *Store) Query(target string, from, to time.Time) ([]Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir, err := s.targetDir(target, false)
+	if err != nil || dir == "" {
+		return []Record{}, err
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []Record{}
+	for _, seg := range segments {
+		// Skip whole segments outside the range
+		if !from.IsZero() && seg.day.Add(24*time.Hour).Before(from) {
+			continue
+		}
+		if !to.IsZero() && !seg.day.Before(to) {
+			continue
+		}
+
+		segRecords, err := readSegment(seg.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range segRecords {
+			if !from.IsZero() && r.Timestamp.Before(from) {
+				continue
+			}
+			if !to.IsZero() && !r.Timestamp.Before(to) {
+				continue
+			}
+			records = append(records, r)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+	return records, nil
+}
+
+// Last returns up to n of the target's most recent records since the given time, oldest first
+func (s *Store) Last(target string, since time.Time, n int) ([]Record, error) {
+	records, err := s.Query(target, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+	return records, nil
+}
+
+// Targets returns every target with stored history
+func (s *Store) Targets() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	targets := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name(), targetFile))
+		if err != nil {
+			continue
+		}
+		targets = append(targets, string(data))
+	}
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// Maintain compacts closed segments and deletes expired ones for every target
+func (s *Store) Maintain(now time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read history directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if err := s.maintainDir(filepath.Join(s.dir, entry.Name()), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==========================
+// Segments
+// ==========================
+
+type segment struct {
+	day       time.Time
+	path      string
+	compacted bool
+}
+
+// targetDir returns the history directory of a target, creating it if asked to.
+// Returns "" if it doesn't exist and create is false.
+func (s *Store) targetDir(target string, create bool) (string, error) {
+	sum := sha256.Sum256([]byte(target))
+	dir := filepath.Join(s.dir, hex.EncodeToString(sum[:8]))
+
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to stat history directory: %w", err)
+	}
+	if !create {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, targetFile), []byte(target), 0644); err != nil {
+		return "", fmt.Errorf("failed to write history target: %w", err)
+	}
+	return dir, nil
+}
+
+// listSegments returns the segments of a target directory, oldest first
+func listSegments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	var segments []segment
+	for _, entry := range entries {
+		name := entry.Name()
+		var seg segment
+		switch {
+		case strings.HasSuffix(name, compactedExt):
+			seg.compacted = true
+			name = strings.TrimSuffix(name, compactedExt)
+		case strings.HasSuffix(name, segmentExt):
+			name = strings.TrimSuffix(name, segmentExt)
+		default:
+			continue
+		}
+		day, err := time.Parse(segmentDateFormat, name)
+		if err != nil {
+			continue
+		}
+		seg.day = day
+		seg.path = filepath.Join(dir, entry.Name())
+		segments = append(segments, seg)
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].day.Before(segments[j].day) })
+	return segments, nil
+}
+
+// maintainDir deletes expired segments and compacts closed ones. Caller must hold the lock.
+func (s *Store) maintainDir(dir string, now time.Time) error {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+
+	today, _ := time.Parse(segmentDateFormat, now.UTC().Format(segmentDateFormat))
+	cutoff := now.Add(-s.retention)
+
+	for _, seg := range segments {
+		switch {
+		case seg.day.Add(24 * time.Hour).Before(cutoff):
+			if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete expired history segment: %w", err)
+			}
+		case !seg.compacted && seg.day.Before(today):
+			if err := compactSegment(seg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compactSegment rewrites a closed segment sorted and gzipped, then removes the original
+func compactSegment(seg segment) error {
+	records, err := readSegment(seg.path)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+
+	// Merge with an existing compacted segment for the same day (late appends)
+	target := strings.TrimSuffix(seg.path, segmentExt) + compactedExt
+	if existing, err := readSegment(target); err == nil {
+		records = append(existing, records...)
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+	}
+
+	tmp := target + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted segment: %w", err)
+	}
+	gz := gzip.NewWriter(f)
+	enc := json.NewEncoder(gz)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write compacted segment: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write compacted segment: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write compacted segment: %w", err)
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to replace compacted segment: %w", err)
+	}
+	return os.Remove(seg.path)
+}
+
+// readSegment reads all records of a plain or compacted segment.
+// A truncated last line (e.g. after a crash) is ignored.
+func readSegment(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open compacted segment: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("failed to read history segment: %w", err)
+	}
+	return records, nil
+}
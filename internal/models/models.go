@@ -3,6 +3,8 @@ package models
 import (
 	"net/http"
 	"time"
+
+	"apiwatcher/internal/wait"
 )
 
 type URLCheck struct {
@@ -25,18 +27,62 @@ type APIRequest struct {
 	RespHeaders map[string]string
 	Body        string
 	Timestamp   time.Time
+
+	// Details captured from the network (zero when unknown)
+	StartedAt    time.Time      // When the request was sent
+	ResourceType string         // Document, XHR, Fetch, ...
+	StatusText   string         // Response status text
+	Protocol     string         // e.g. http/1.1, h2
+	MIMEType     string         // Response MIME type
+	Timing       *RequestTiming // Where the time went (nil if the request got no response)
+	RequestSize  int64          // Request body bytes
+	RequestBody  string         // Request body (may be truncated)
+	TransferSize int64          // Response bytes on the wire (headers and encoded body)
+	BodySize     int64          // Decoded response body bytes
+	Error        string         // Network error if the request failed without a response
+}
+
+// RequestTiming breaks down the duration of a request in milliseconds.
+// Phases that didn't happen (e.g. DNS and connect on a reused connection) are -1.
+type RequestTiming struct {
+	Blocked float64 `json:"blocked"` // Queued or stalled before DNS/connect/send started
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"` // Includes TLS
+	TLS     float64 `json:"tls"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`    // Time to first byte after the request was sent
+	Receive float64 `json:"receive"` // Downloading the response body
+	Total   float64 `json:"total"`
+}
+
+// TTFB returns the time from the start of the request until the first response byte
+func (t *RequestTiming) TTFB() float64 {
+	return t.Total - t.Receive
+}
+
+// Locator is one way of finding the element of a recorded action
+type Locator struct {
+	Kind  string `json:"kind"`           // testid, role, text, css or xpath
+	Value string `json:"value"`          // Test ID, role, text, selector or expression
+	Name  string `json:"name,omitempty"` // Accessible name (role) or test attribute (testid)
 }
 
 type SnapshotAction struct {
-	Type      string `json:"type"`
-	Selector  string `json:"selector,omitempty"`
-	Value     string `json:"value,omitempty"`
-	Text      string `json:"text,omitempty"`      // Element text content (for dropdown suggestions)
-	Classes   string `json:"classes,omitempty"`   // Element classes (for better targeting)
-	AriaLabel string `json:"ariaLabel,omitempty"` // ARIA label (for accessibility-aware targeting)
-	Key       string `json:"key,omitempty"`       // Keyboard key (for keydown events)
-	Timestamp int64  `json:"timestamp,omitempty"`
-	URL       string `json:"url,omitempty"`
+	Type      string         `json:"type"`
+	Selector  string         `json:"selector,omitempty"`
+	Value     string         `json:"value,omitempty"`
+	Text      string         `json:"text,omitempty"`      // Element text content (for dropdown suggestions)
+	Classes   string         `json:"classes,omitempty"`   // Element classes (for better targeting)
+	AriaLabel string         `json:"ariaLabel,omitempty"` // ARIA label (for accessibility-aware targeting)
+	Key       string         `json:"key,omitempty"`       // Keyboard key (for keydown events)
+	Timestamp int64          `json:"timestamp,omitempty"`
+	URL       string         `json:"url,omitempty"`
+	Optional  bool           `json:"optional,omitempty"`  // A failure of this action doesn't fail the replay
+	Pattern   string         `json:"pattern,omitempty"`   // URL regex (assert_url, assert_api)
+	Status    int            `json:"status,omitempty"`    // Expected response status (assert_api), 0 = any below 400
+	Wait      *wait.Strategy `json:"wait,omitempty"`      // What to wait for after the action (nil = network idle)
+	Locators  []Locator      `json:"locators,omitempty"`  // Ways to find the element, best first (falls back to Selector)
+	Sensitive bool           `json:"sensitive,omitempty"` // Typed into a password field, or Value references a secret
 }
 
 func (u *URLCheck) Check() {
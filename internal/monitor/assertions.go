@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"apiwatcher/internal/config"
+)
+
+// ResponseSample is a captured response that assertions are evaluated against
+type ResponseSample struct {
+	URL      string
+	Status   int
+	Headers  map[string]string
+	Body     []byte
+	Latency  time.Duration
+	IsTarget bool // The target's own response (probe response or main document)
+}
+
+// header returns a response header using a case-insensitive lookup
+func (r *ResponseSample) header(name string) (string, bool) {
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// EvaluateAssertions runs every applicable assertion against a response and
+// returns a readable reason for each one that failed
+func EvaluateAssertions(assertions []config.Assertion, sample *ResponseSample) []string {
+	var failures []string
+	for _, a := range assertions {
+		if !a.Matches(sample.URL, sample.IsTarget) {
+			continue
+		}
+		if err := evaluateAssertion(a, sample); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s failed: %v", sample.URL, a.Describe(), err))
+		}
+	}
+	return failures
+}
+
+// evaluateAssertion checks a single assertion, returning why it failed
+func evaluateAssertion(a config.Assertion, sample *ResponseSample) error {
+	switch a.Type {
+	case config.AssertJSONPath:
+		var doc interface{}
+		if err := json.Unmarshal(sample.Body, &doc); err != nil {
+			return fmt.Errorf("body is not valid JSON")
+		}
+		value, err := evalJSONPath(doc, a.Path)
+		if err != nil {
+			return err
+		}
+		if actual := jsonValueString(value); actual != a.Expected {
+			return fmt.Errorf("got %q", actual)
+		}
+
+	case config.AssertBodyRegex:
+		re, err := a.BodyPattern()
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+		if !re.Match(sample.Body) {
+			return fmt.Errorf("pattern not found in body")
+		}
+
+	case config.AssertHeader:
+		value, ok := sample.header(a.Header)
+		if !ok {
+			return fmt.Errorf("header missing")
+		}
+		if a.Expected != "" && value != a.Expected {
+			return fmt.Errorf("got %q", value)
+		}
+
+	case config.AssertContentType:
+		value, _ := sample.header("Content-Type")
+		if !strings.HasPrefix(strings.ToLower(value), strings.ToLower(a.Expected)) {
+			return fmt.Errorf("got %q", value)
+		}
+
+	case config.AssertMaxLatency:
+		limit := time.Duration(a.MaxLatencyMs) * time.Millisecond
+		if sample.Latency > limit {
+			return fmt.Errorf("took %v", sample.Latency.Round(time.Millisecond))
+		}
+
+	default:
+		return fmt.Errorf("unknown assertion type %q", a.Type)
+	}
+	return nil
+}
+
+// ==========================
+// Minimal JSONPath
+// ==========================
+
+// evalJSONPath resolves a simple JSONPath ($.a.b[0]['c']) against a decoded JSON document
+func evalJSONPath(doc interface{}, path string) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+
+	current := doc
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed [ in path %s", path)
+			}
+			token := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if unquoted, ok := unquoteKey(token); ok {
+				obj, isObj := current.(map[string]interface{})
+				if !isObj {
+					return nil, fmt.Errorf("%s is not an object", token)
+				}
+				value, exists := obj[unquoted]
+				if !exists {
+					return nil, fmt.Errorf("key %q not found", unquoted)
+				}
+				current = value
+				continue
+			}
+
+			idx, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+			arr, isArr := current.([]interface{})
+			if !isArr {
+				return nil, fmt.Errorf("[%d] applied to non-array", idx)
+			}
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, fmt.Errorf("index %d out of range", idx)
+			}
+			current = arr[idx]
+
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %s", path)
+			}
+
+			obj, isObj := current.(map[string]interface{})
+			if !isObj {
+				return nil, fmt.Errorf("%s is not an object", key)
+			}
+			value, exists := obj[key]
+			if !exists {
+				return nil, fmt.Errorf("key %q not found", key)
+			}
+			current = value
+
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %s", rest, path)
+		}
+	}
+	return current, nil
+}
+
+// unquoteKey strips matching single or double quotes from a bracket token
+func unquoteKey(token string) (string, bool) {
+	if len(token) >= 2 {
+		first, last := token[0], token[len(token)-1]
+		if (first == '\'' || first == '"') && first == last {
+			return token[1 : len(token)-1], true
+		}
+	}
+	return "", false
+}
+
+// jsonValueString renders a decoded JSON value for comparison with an expected string
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
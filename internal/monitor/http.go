@@ -0,0 +1,198 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/models"
+)
+
+// maxProbeBodySize caps how much of a response body is read for alerts and assertions
+const maxProbeBodySize = 64 * 1024
+
+// ==========================
+// Lightweight HTTP Probe
+// ==========================
+
+// ProbeHTTP checks a target with a single plain HTTP request instead of launching Chrome.
+// It returns the same CheckResult as CheckWebsite so both modes share the alert path.
+func ProbeHTTP(parentCtx context.Context, url string, probe *config.HTTPProbe, assertions []config.Assertion) (*CheckResult, error) {
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(parentCtx, probe.GetTimeout())
+	defer cancel()
+
+	method := probe.GetMethod()
+	var body io.Reader
+	var requestSize int64
+	if probe != nil && probe.Body != "" {
+		body = strings.NewReader(probe.Body)
+		requestSize = int64(len(probe.Body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build probe request: %w", err)
+	}
+
+	reqHeaders := make(map[string]string)
+	if probe != nil {
+		for k, v := range probe.Headers {
+			req.Header.Set(k, v)
+			reqHeaders[k] = v
+		}
+	}
+
+	fmt.Printf("    🌐 Probing %s %s...\n", method, url)
+	trace := &probeTrace{}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()))
+	probeStart := time.Now()
+
+	resp, err := http.DefaultClient.Do(req)
+	probeDuration := time.Since(probeStart)
+	if err != nil {
+		fmt.Printf("    ❌ Probe error after %v: %v\n", probeDuration, err)
+		failed := models.NewAPIRequest(url, method, 0, reqHeaders, nil, err.Error())
+		failed.StartedAt = probeStart
+		failed.Error = err.Error()
+		failed.RequestSize = requestSize
+		return &CheckResult{
+			BadRequests: []*models.APIRequest{failed},
+			Requests:    []*models.APIRequest{failed},
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxProbeBodySize))
+	// Drain the rest so the transfer size and download time are complete
+	remaining, _ := io.Copy(io.Discard, resp.Body)
+	probeEnd := time.Now()
+	status := resp.StatusCode
+
+	fmt.Printf("    📡 [%d] %s %s in %v\n", status, method, url, probeDuration)
+
+	respHeaders := make(map[string]string, len(resp.Header))
+	for k := range resp.Header {
+		respHeaders[k] = resp.Header.Get(k)
+	}
+
+	captured := models.NewAPIRequest(url, method, status, reqHeaders, respHeaders, string(respBody))
+	captured.StartedAt = probeStart
+	captured.ResourceType = "Probe"
+	captured.StatusText = http.StatusText(status)
+	captured.Protocol = strings.ToLower(resp.Proto)
+	captured.MIMEType = resp.Header.Get("Content-Type")
+	captured.Timing = trace.timing(probeStart, probeEnd)
+	captured.RequestSize = requestSize
+	captured.BodySize = int64(len(respBody)) + remaining
+	captured.TransferSize = captured.BodySize
+	if resp.ContentLength >= 0 && !resp.Uncompressed {
+		captured.TransferSize = resp.ContentLength
+	}
+
+	result := &CheckResult{Requests: []*models.APIRequest{captured}}
+	if !probe.IsExpectedStatus(status) {
+		fmt.Printf("    ⚠️  BAD API: %d -> %s\n", status, url)
+		result.BadRequests = append(result.BadRequests, captured)
+	}
+
+	result.AssertionFailures = EvaluateAssertions(assertions, &ResponseSample{
+		URL:      url,
+		Status:   status,
+		Headers:  respHeaders,
+		Body:     respBody,
+		Latency:  probeDuration,
+		IsTarget: true,
+	})
+	for _, failure := range result.AssertionFailures {
+		fmt.Printf("    ⚠️  ASSERTION: %s\n", failure)
+	}
+
+	return result, nil
+}
+
+// ==========================
+// Probe Timing
+// ==========================
+
+// probeTrace records when each phase of a probe request happened
+type probeTrace struct {
+	mutex        sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+}
+
+func (t *probeTrace) clientTrace() *httptrace.ClientTrace {
+	record := func(field *time.Time) {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { record(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+}
+
+// timing converts the recorded phases into a request timing.
+// Connect includes TLS, matching the browser timings.
+func (t *probeTrace) timing(start, end time.Time) *models.RequestTiming {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	span := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return -1
+		}
+		return float64(to.Sub(from)) / float64(time.Millisecond)
+	}
+
+	timing := &models.RequestTiming{
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, t.tlsDone),
+		TLS:     span(t.tlsStart, t.tlsDone),
+		Send:    max(0, span(t.gotConn, t.wroteRequest)),
+		Wait:    max(0, span(t.wroteRequest, t.firstByte)),
+		Receive: max(0, span(t.firstByte, end)),
+		Total:   span(start, end),
+	}
+	if t.tlsDone.IsZero() {
+		timing.Connect = span(t.connectStart, t.connectDone)
+	}
+
+	// Whatever happened before DNS, connect or sending (e.g. waiting for a pooled connection)
+	firstPhase := t.gotConn
+	for _, phaseStart := range []time.Time{t.dnsStart, t.connectStart} {
+		if !phaseStart.IsZero() && phaseStart.Before(firstPhase) {
+			firstPhase = phaseStart
+		}
+	}
+	timing.Blocked = max(0, span(start, firstPhase))
+	return timing
+}
@@ -3,19 +3,50 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
 	"apiwatcher/internal/models"
+	"apiwatcher/internal/netcapture"
+	"apiwatcher/internal/tracing"
+	"apiwatcher/internal/wait"
 
+	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/chromedp"
 )
 
+// CheckResult holds everything observed during a single website check
+type CheckResult struct {
+	BadRequests       []*models.APIRequest // Requests that failed (status or navigation errors)
+	AssertionFailures []string             // Readable reason for each failed assertion
+	Requests          []*models.APIRequest // Every non-static request with headers, sizes and timing
+	HAR               *har.HAR             // The whole session (only when recorded)
+}
+
+// CheckOptions configures a browser check
+type CheckOptions struct {
+	Assertions []config.Assertion // Response assertions for the target and captured requests
+	RecordHAR  bool               // Record every request of the session, static assets included, as a HAR
+	Wait       *wait.Strategy     // When the page counts as loaded (nil = defaultCheckWait)
+}
+
+// defaultCheckWait waits for the page and its API calls to finish loading
+var defaultCheckWait = wait.NetworkIdle(time.Second, wait.DefaultTimeout)
+
 // ==========================
 // Website Monitoring
 // ==========================
-func CheckWebsite(parentCtx context.Context, url string) ([]*models.APIRequest, error) {
+func CheckWebsite(parentCtx context.Context, url string, assertions []config.Assertion) (*CheckResult, error) {
+	return CheckWebsiteWithOptions(parentCtx, url, CheckOptions{Assertions: assertions})
+}
+
+// CheckWebsiteWithOptions loads a website in Chrome and reports failed requests and assertions
+func CheckWebsiteWithOptions(parentCtx context.Context, url string, options CheckOptions) (*CheckResult, error) {
+	assertions := options.Assertions
+
 	// If no context provided, use background
 	if parentCtx == nil {
 		parentCtx = context.Background()
@@ -35,22 +66,57 @@ func CheckWebsite(parentCtx context.Context, url string) ([]*models.APIRequest,
 	ctx, cancel := chromedp.NewContext(allocCtx)
 	defer cancel()
 
-	var badRequests []*models.APIRequest
+	result := &CheckResult{}
 	requestCount := 0
 	okCount := 0
 	errorCount := 0
 
+	// Responses that assertions apply to, keyed by request ID until they finished
+	// loading. Bodies are fetched once the page settled, not from the listener.
+	var samplesMu sync.Mutex
+	pendingSamples := make(map[network.RequestID]*ResponseSample)
+	loadedSamples := make(map[network.RequestID]*ResponseSample)
+	var samples []*ResponseSample
+	mainDocumentSeen := false
+
+	// Captured responses become child spans of the check span (if tracing)
+	spans := tracing.NewNetworkRecorder(tracing.SpanFromContext(parentCtx))
+
+	// Full request details, collected for the non-static requests once the page settled
+	capture := netcapture.NewRecorder()
+	var captured []*network.EventResponseReceived
+
+	tracker := wait.NewTracker()
 	chromedp.ListenTarget(ctx, func(ev interface{}) {
-		if resp, ok := ev.(*network.EventResponseReceived); ok {
+		capture.Handle(ev)
+		tracker.Handle(ev)
+
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			spans.RequestSent(ev)
+
+		case *network.EventResponseReceived:
+			resp := ev
 			apiURL := resp.Response.URL
 
+			// The first document response is the target itself
+			isTarget := false
+			if resp.Type == network.ResourceTypeDocument && !mainDocumentSeen {
+				mainDocumentSeen = true
+				isTarget = true
+			}
+
 			// Skip static file types
-			if config.IsStaticAsset(apiURL) {
+			if !isTarget && config.IsStaticAsset(apiURL) {
 				return
 			}
 
 			requestCount++
 			status := int(resp.Response.Status)
+			spans.ResponseReceived(resp)
+			samplesMu.Lock()
+			captured = append(captured, resp)
+			samplesMu.Unlock()
 
 			// Log EVERY non-static HTTP request with timing
 			fmt.Printf("    📡 [%d] %s\n", status, apiURL)
@@ -58,10 +124,35 @@ func CheckWebsite(parentCtx context.Context, url string) ([]*models.APIRequest,
 			if status >= 400 {
 				errorCount++
 				fmt.Printf("    ⚠️  BAD API: %d -> %s\n", status, apiURL)
-				badRequests = append(badRequests, models.NewAPIRequest(apiURL, "", status, nil, nil, ""))
 			} else {
 				okCount++
 			}
+
+			// Keep the response for assertions if any apply to it
+			if !hasMatchingAssertion(assertions, apiURL, isTarget) {
+				return
+			}
+			sample := &ResponseSample{
+				URL:      apiURL,
+				Status:   status,
+				Headers:  netcapture.HeaderStrings(resp.Response.Headers),
+				IsTarget: isTarget,
+			}
+			if timing := resp.Response.Timing; timing != nil {
+				sample.Latency = time.Duration(timing.ReceiveHeadersEnd * float64(time.Millisecond))
+			}
+			samplesMu.Lock()
+			samples = append(samples, sample)
+			pendingSamples[resp.RequestID] = sample
+			samplesMu.Unlock()
+
+		case *network.EventLoadingFinished:
+			samplesMu.Lock()
+			if sample, tracked := pendingSamples[ev.RequestID]; tracked {
+				delete(pendingSamples, ev.RequestID)
+				loadedSamples[ev.RequestID] = sample
+			}
+			samplesMu.Unlock()
 		}
 	})
 
@@ -71,19 +162,105 @@ func CheckWebsite(parentCtx context.Context, url string) ([]*models.APIRequest,
 	err := chromedp.Run(ctx,
 		network.Enable(),
 		chromedp.Navigate(url),
-		chromedp.Sleep(time.Duration(config.WorkerSleepTime)*time.Second),
 	)
+	var waitErr error
+	if err == nil {
+		waitErr = waitForPage(ctx, tracker, options.Wait)
+	}
 
 	scanDuration := time.Since(scanStart)
 
+	samplesMu.Lock()
+	for _, resp := range captured {
+		req := capture.Get(resp.RequestID)
+		if req == nil {
+			// The request was sent before it could be observed
+			req = models.NewAPIRequest(resp.Response.URL, "", int(resp.Response.Status), nil, netcapture.HeaderStrings(resp.Response.Headers), "")
+		}
+		result.Requests = append(result.Requests, req)
+		if req.StatusCode >= 400 {
+			result.BadRequests = append(result.BadRequests, req)
+		}
+	}
+	samplesMu.Unlock()
+
 	if err != nil {
 		fmt.Printf("    ❌ Navigation error after %v: %v\n", scanDuration, err)
-		badRequests = append(badRequests, models.NewAPIRequest(url, "", 0, nil, nil, err.Error()))
+		result.BadRequests = append(result.BadRequests, models.NewAPIRequest(url, "", 0, nil, nil, err.Error()))
+	}
+
+	// Evaluate assertions once the bodies of the loaded responses have been fetched
+	loadSampleBodies(ctx, &samplesMu, loadedSamples)
+	samplesMu.Lock()
+	for _, sample := range samples {
+		result.AssertionFailures = append(result.AssertionFailures, EvaluateAssertions(assertions, sample)...)
+	}
+	samplesMu.Unlock()
+	if waitErr != nil {
+		result.AssertionFailures = append(result.AssertionFailures, "wait: "+waitErr.Error())
+	}
+
+	if options.RecordHAR {
+		capture.LoadBodies(ctx)
+		result.HAR = har.Build(url, scanStart, capture.Requests())
 	}
 
 	// Summary log
-	fmt.Printf("    📊 Summary: %d total requests (%d OK, %d errors) in %v\n",
-		requestCount, okCount, errorCount, scanDuration)
+	fmt.Printf("    📊 Summary: %d total requests (%d OK, %d errors, %d failed assertions) in %v\n",
+		requestCount, okCount, errorCount, len(result.AssertionFailures), scanDuration)
+
+	return result, nil
+}
+
+// loadSampleBodies fetches the bodies of the responses that finished loading
+func loadSampleBodies(ctx context.Context, mutex *sync.Mutex, loaded map[network.RequestID]*ResponseSample) {
+	mutex.Lock()
+	ids := make([]network.RequestID, 0, len(loaded))
+	for id := range loaded {
+		ids = append(ids, id)
+	}
+	mutex.Unlock()
 
-	return badRequests, nil
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	executor := cdp.WithExecutor(ctx, c.Target)
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		body, err := network.GetResponseBody(id).Do(executor)
+		if err != nil {
+			continue
+		}
+		mutex.Lock()
+		loaded[id].Body = body
+		mutex.Unlock()
+	}
+}
+
+// waitForPage waits until the page counts as loaded. A page whose network never goes
+// idle (polling, analytics) is checked anyway; a selector or response that never
+// shows up is returned as an error.
+func waitForPage(ctx context.Context, tracker *wait.Tracker, strategy *wait.Strategy) error {
+	if strategy == nil {
+		strategy = defaultCheckWait
+	}
+	err := tracker.Wait(ctx, strategy, 0)
+	if err != nil && strategy.Kind == wait.KindNetworkIdle {
+		fmt.Printf("    ⏳ %v, checking the page anyway\n", err)
+		return nil
+	}
+	return err
+}
+
+// hasMatchingAssertion reports whether any assertion applies to the given response
+func hasMatchingAssertion(assertions []config.Assertion, url string, isTarget bool) bool {
+	for _, a := range assertions {
+		if a.Matches(url, isTarget) {
+			return true
+		}
+	}
+	return false
 }
@@ -2,10 +2,18 @@ package monitor
 
 import (
 	"apiwatcher/internal/alert"
-	"apiwatcher/internal/email"
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
+	"apiwatcher/internal/models"
+	"apiwatcher/internal/notify"
 	"apiwatcher/internal/snapshot"
+	"apiwatcher/internal/tracing"
+	"apiwatcher/internal/wait"
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,31 +26,49 @@ type Logger interface {
 // Job Structures
 // ==========================
 type APIJob struct {
-	Website string
-	Email   string
+	Website             string
+	Email               string               // Alert recipients (comma separated)
+	Probe               *config.HTTPProbe    // Optional HTTP probe (nil = browser check)
+	Assertions          []config.Assertion   // Response assertions for the target and captured requests
+	Timeout             time.Duration        // Overall check timeout (0 = no limit)
+	Policy              *config.AlertPolicy  // Alert throttling and escalation (nil = default policy)
+	ConsecutiveFailures int                  // Consecutive failed checks before this one
+	Silences            *alert.SilenceStore  // Active silences (nil = never silenced)
+	Incidents           *alert.IncidentStore // Open incidents (nil = plain cooldown)
+	Maintenance         bool                 // Check runs inside a maintenance window (no alerts)
+	RecordHAR           bool                 // Record the check as a HAR (JobResult.HAR)
+	Wait                *wait.Strategy       // When the page counts as loaded (nil = network idle)
 }
 
 type SnapshotJob struct {
-	Website   string
-	Email     string // Email address for sending alerts on errors
-	Snapshots []*snapshot.Snapshot // Multiple snapshots per URL
+	Website     string
+	Email       string               // Email address for sending alerts on errors
+	Snapshots   []*snapshot.Snapshot // Multiple snapshots per URL
+	Policy      *config.AlertPolicy  // Alert throttling and escalation (nil = default policy)
+	Silences    *alert.SilenceStore  // Active silences (nil = never silenced)
+	Incidents   *alert.IncidentStore // Open incidents (nil = plain cooldown)
+	Maintenance bool                 // Replays run inside a maintenance window (no alerts)
+	RecordHAR   bool                 // Record each replay as a HAR (ReplayResult.HAR)
 }
 
 // Legacy Job struct (kept for backwards compatibility during transition)
 type Job struct {
-	Website  string
-	Email    string
+	APIJob
 	Snapshot *snapshot.Snapshot
 }
 
 // JobResult contains the result of processing a job
 type JobResult struct {
-	Success     bool
-	Duration    time.Duration
-	AlertSent   bool
-	ErrorCount  int
-	SnapshotRan bool
-	Error       error
+	Success           bool
+	Duration          time.Duration
+	AlertSent         bool
+	ErrorCount        int
+	AssertionFailures []string             // Readable reason for each failed assertion
+	BadRequests       []*models.APIRequest // Failed API calls seen during the check
+	Requests          []*models.APIRequest // Every non-static request with headers, sizes and timing
+	HAR               *har.HAR             // The recorded session (only with RecordHAR)
+	SnapshotRan       bool
+	Error             error
 }
 
 // ==========================
@@ -56,57 +82,11 @@ func Worker(id int, jobs <-chan Job, logger Logger) {
 
 // ProcessJob handles a single monitoring job with optional context for cancellation
 func ProcessJob(ctx context.Context, id int, job Job, logger Logger) JobResult {
-	result := JobResult{
-		Success:    true,
-		AlertSent:  false,
-		ErrorCount: 0,
-	}
-
-	// Check if context is cancelled before starting
-	if ctx != nil {
-		select {
-		case <-ctx.Done():
-			logger.Logf("[WORKER %d] Aborted before starting %s", id, job.Website)
-			result.Success = false
-			result.Error = ctx.Err()
-			return result
-		default:
-		}
-	}
-	startTime := time.Now()
-	logger.Logf("[WORKER %d] ⏱️  START checking %s", id, job.Website)
-
-	// Check the website with context
-	badRequests, err := CheckWebsite(ctx, job.Website)
-	result.Duration = time.Since(startTime)
-
-	if err != nil {
-		logger.Logf("[WORKER %d] ❌ ERROR after %v: %v", id, result.Duration, err)
-		result.Success = false
-		result.Error = err
+	result := ProcessAPIJob(ctx, id, job.APIJob, logger)
+	if result.Error != nil {
 		return result
 	}
 
-	logger.Logf("[WORKER %d] 🔍 Scan completed in %v for %s", id, result.Duration, job.Website)
-
-	// Load alert log
-	alertLog, _ := alert.LoadLog()
-
-	// Handle failed requests
-	if len(badRequests) > 0 {
-		result.Success = false
-		result.ErrorCount = len(badRequests)
-
-		body := "The following API calls failed:\n\n"
-		for _, r := range badRequests {
-			body += fmt.Sprintf("%d %s\n", r.StatusCode, r.URL)
-		}
-
-		result.AlertSent = sendErrorAlert(job.Website, job.Email, "⚠️ API Errors Detected", body, alertLog, logger)
-	} else {
-		logger.Logf("[OK] No API errors detected for %s", job.Website)
-	}
-
 	// Run snapshot if configured
 	if job.Snapshot != nil {
 		snapshotStartTime := time.Now()
@@ -128,6 +108,101 @@ func ProcessJob(ctx context.Context, id int, job Job, logger Logger) JobResult {
 	return result
 }
 
+// checkTarget runs the HTTP probe when one is configured, otherwise the full browser check
+func checkTarget(ctx context.Context, website string, probe *config.HTTPProbe, opts CheckOptions, timeout time.Duration) (*CheckResult, error) {
+	if timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if probe != nil {
+		start := time.Now()
+		result, err := ProbeHTTP(ctx, website, probe, opts.Assertions)
+		if err == nil && opts.RecordHAR {
+			result.HAR = har.Build(website, start, result.Requests)
+		}
+		return result, err
+	}
+	return CheckWebsiteWithOptions(ctx, website, opts)
+}
+
+// startCheckSpan starts the trace span of a check run
+func startCheckSpan(ctx context.Context, id int, website string, probe *config.HTTPProbe, maintenance bool) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, "check")
+	mode := config.CheckModeBrowser
+	if probe != nil {
+		mode = config.CheckModeHTTP
+	}
+	span.SetAttribute("apiwatcher.target", website)
+	span.SetAttribute("apiwatcher.check_mode", mode)
+	span.SetAttribute("apiwatcher.worker", id)
+	span.SetAttribute("apiwatcher.maintenance", maintenance)
+	return ctx, span
+}
+
+// finishCheckSpan records the outcome of a check run and ends its span
+func finishCheckSpan(span *tracing.Span, result JobResult) {
+	span.SetAttribute("apiwatcher.success", result.Success)
+	span.SetAttribute("apiwatcher.error_count", result.ErrorCount)
+	span.SetAttribute("apiwatcher.alert_sent", result.AlertSent)
+	switch {
+	case result.Error != nil:
+		span.SetError(result.Error.Error())
+	case !result.Success:
+		span.SetError(fmt.Sprintf("%d failed request(s), %d failed assertion(s)",
+			len(result.BadRequests), len(result.AssertionFailures)))
+	}
+	span.Finish()
+}
+
+// buildFailureBody builds the alert body for failed requests and assertions
+func buildFailureBody(badRequests []*models.APIRequest, assertionFailures []string) string {
+	body := ""
+	if len(badRequests) > 0 {
+		body += "The following API calls failed:\n\n"
+		for _, r := range badRequests {
+			body += fmt.Sprintf("%d %s\n", r.StatusCode, r.URL)
+		}
+	}
+	if len(assertionFailures) > 0 {
+		if body != "" {
+			body += "\n"
+		}
+		body += "The following assertions failed:\n\n"
+		for _, failure := range assertionFailures {
+			body += failure + "\n"
+		}
+	}
+	return body
+}
+
+// newCheckAlert builds the alert for a failed website check
+func newCheckAlert(website string, badRequests []*models.APIRequest, assertionFailures []string) notify.Alert {
+	return notify.Alert{
+		Key:               website,
+		Kind:              notify.KindError,
+		Website:           website,
+		Subject:           "⚠️ API Errors Detected",
+		Body:              buildFailureBody(badRequests, assertionFailures),
+		Failures:          failedRequests(badRequests),
+		AssertionFailures: assertionFailures,
+		Timestamp:         time.Now(),
+	}
+}
+
+// failedRequests converts captured requests to alert failures
+func failedRequests(requests []*models.APIRequest) []notify.FailedRequest {
+	failures := make([]notify.FailedRequest, 0, len(requests))
+	for _, r := range requests {
+		failures = append(failures, notify.FailedRequest{URL: r.URL, StatusCode: r.StatusCode})
+	}
+	return failures
+}
+
 // ==========================
 // Two-Phase Processing
 // ==========================
@@ -152,11 +227,14 @@ func ProcessAPIJob(ctx context.Context, id int, job APIJob, logger Logger) JobRe
 		}
 	}
 
+	ctx, span := startCheckSpan(ctx, id, job.Website, job.Probe, job.Maintenance)
+	defer func() { finishCheckSpan(span, result) }()
+
 	startTime := time.Now()
 	logger.Logf("[WORKER %d] ⏱️  START checking %s", id, job.Website)
 
 	// Check the website with context
-	badRequests, err := CheckWebsite(ctx, job.Website)
+	checkResult, err := checkTarget(ctx, job.Website, job.Probe, CheckOptions{Assertions: job.Assertions, RecordHAR: job.RecordHAR, Wait: job.Wait}, job.Timeout)
 	result.Duration = time.Since(startTime)
 
 	if err != nil {
@@ -171,31 +249,79 @@ func ProcessAPIJob(ctx context.Context, id int, job APIJob, logger Logger) JobRe
 	// Load alert log
 	alertLog, _ := alert.LoadLog()
 
-	// Handle failed requests
-	if len(badRequests) > 0 {
+	// Handle failed requests and assertions
+	badRequests := checkResult.BadRequests
+	result.BadRequests = badRequests
+	result.Requests = checkResult.Requests
+	result.HAR = checkResult.HAR
+	result.AssertionFailures = checkResult.AssertionFailures
+	if len(badRequests) > 0 || len(result.AssertionFailures) > 0 {
 		result.Success = false
-		result.ErrorCount = len(badRequests)
+		result.ErrorCount = len(badRequests) + len(result.AssertionFailures)
 
-		body := "The following API calls failed:\n\n"
-		for _, r := range badRequests {
-			body += fmt.Sprintf("%d %s\n", r.StatusCode, r.URL)
+		for _, failure := range result.AssertionFailures {
+			logger.Logf("[ASSERTION] ❌ %s", failure)
 		}
 
-		result.AlertSent = sendErrorAlert(job.Website, job.Email, "⚠️ API Errors Detected", body, alertLog, logger)
+		if job.Maintenance {
+			logger.Logf("[MAINTENANCE] 🔧 Skipping alert for %s (maintenance window)", job.Website)
+		} else {
+			checkAlert := newCheckAlert(job.Website, badRequests, result.AssertionFailures)
+			result.AlertSent = sendErrorAlert(ctx, checkAlert, job.Email, job.Policy, job.ConsecutiveFailures+1, job.Silences, job.Incidents, alertLog, logger)
+		}
 	} else {
 		logger.Logf("[OK] No API errors detected for %s", job.Website)
+		resolveIncident(job.Incidents, job.Website, logger)
 	}
 
 	return result
 }
 
+// Snapshot replay outcomes
+const (
+	SnapshotPassed      = "passed"       // Replay completed without API errors
+	SnapshotAPIErrors   = "api_errors"   // Replay completed but API calls failed
+	SnapshotStepsFailed = "steps_failed" // Replay completed but required steps failed
+	SnapshotFailed      = "failed"       // Replay could not be completed
+)
+
+// SnapshotRun is the outcome of a single snapshot replay
+type SnapshotRun struct {
+	SnapshotID string
+	Version    int // Snapshot version that ran
+	Result     *snapshot.ReplayResult
+	Error      error
+}
+
+// Outcome returns SnapshotPassed, SnapshotAPIErrors, SnapshotStepsFailed or SnapshotFailed
+func (r SnapshotRun) Outcome() string {
+	switch {
+	case r.Error != nil || r.Result == nil:
+		return SnapshotFailed
+	case len(r.Result.FailedSteps()) > 0:
+		return SnapshotStepsFailed
+	case len(r.Result.APIErrors) > 0:
+		return SnapshotAPIErrors
+	default:
+		return SnapshotPassed
+	}
+}
+
 // ProcessSnapshots handles all snapshot replays for a website sequentially (Phase 2)
 // This is called AFTER all API checks are complete
-func ProcessSnapshots(job SnapshotJob, logger Logger) {
+func ProcessSnapshots(job SnapshotJob, logger Logger) []SnapshotRun {
 	if len(job.Snapshots) == 0 {
-		return
+		return nil
 	}
 
+	runs := make([]SnapshotRun, 0, len(job.Snapshots))
+
+	ctx, span := tracing.Start(context.Background(), "snapshots")
+	span.SetAttribute("apiwatcher.target", job.Website)
+	span.SetAttribute("apiwatcher.snapshot_count", len(job.Snapshots))
+	span.SetAttribute("apiwatcher.maintenance", job.Maintenance)
+	defer func() { finishSnapshotsSpan(span, runs) }()
+
 	logger.Logf("[SNAPSHOTS] Processing %d snapshot(s) for %s", len(job.Snapshots), job.Website)
 
 	for _, snap := range job.Snapshots {
@@ -203,74 +329,303 @@ func ProcessSnapshots(job SnapshotJob, logger Logger) {
 			continue
 		}
 
-		logger.Logf("[SNAPSHOT] 🎬 Starting replay for %s (ID: %s, Actions: %d)",
-			job.Website, snap.ID, len(snap.Actions))
-
-		// Use ReplayWithResult to get detailed error information
-		result, err := snapshot.ReplayWithResult(snap)
-		if err != nil {
-			logger.Logf("[SNAPSHOT] ❌ Replay FAILED after %v for %s (ID: %s): %v",
-				result.Duration, job.Website, snap.ID, err)
-		} else if len(result.APIErrors) > 0 {
-			// Snapshot completed but with API errors detected
-			logger.Logf("[SNAPSHOT] ⚠️  Replay completed with %d API errors for %s (ID: %s)",
-				len(result.APIErrors), job.Website, snap.ID)
-
-			// Send alert email for snapshot API errors
-			if job.Email != "" {
-				alertLog, _ := alert.LoadLog()
-				body := fmt.Sprintf(`Snapshot Replay Error Alert
-
-Snapshot: %s
+		logger.Logf("[SNAPSHOT] 🎬 Starting replay for %s (ID: %s, Version: %d, Actions: %d)",
+			job.Website, snap.ID, snap.Version, len(snap.Actions))
+
+		// Replay with a child span so captured API responses are traced under it
+		replayCtx, replaySpan := tracing.Start(ctx, "snapshot.replay")
+		replaySpan.SetAttribute("apiwatcher.snapshot_id", snap.ID)
+		replaySpan.SetAttribute("apiwatcher.snapshot_version", snap.Version)
+		replaySpan.SetAttribute("apiwatcher.snapshot_actions", len(snap.Actions))
+		result, err := snapshot.ReplayWithOptions(replayCtx, snap, snapshot.ReplayOptions{RecordHAR: job.RecordHAR})
+		run := SnapshotRun{SnapshotID: snap.ID, Version: snap.Version, Result: result, Error: err}
+		runs = append(runs, run)
+		finishReplaySpan(replaySpan, run)
+		if err != nil || !result.Success {
+			steps := failedSteps(result)
+			if err != nil {
+				logger.Logf("[SNAPSHOT] ❌ Replay FAILED after %v for %s (ID: %s): %v",
+					result.Duration, job.Website, snap.ID, err)
+			} else {
+				// Snapshot completed but with API errors or failed steps
+				logger.Logf("[SNAPSHOT] ⚠️  Replay completed with %d API errors and %d failed step(s) for %s (ID: %s)",
+					len(result.APIErrors), len(steps), job.Website, snap.ID)
+			}
+
+			if job.Maintenance {
+				logger.Logf("[MAINTENANCE] 🔧 Skipping snapshot alert for %s (maintenance window)", job.Website)
+				continue
+			}
+
+			// Send alert for snapshot API errors
+			alertLog, _ := alert.LoadLog()
+			body := fmt.Sprintf(`Snapshot Replay Error Alert
+
+Snapshot: %s (version %d)
 Website: %s
 
 API Errors Detected: %d
+Failed Steps: %d of %d
+`, snap.ID, snap.Version, job.Website, len(result.APIErrors), len(steps), len(result.Steps))
+			if err != nil {
+				body += fmt.Sprintf("\nReplay failed: %v\n", err)
+			}
 
-Failed API Calls:
-`, snap.ID, job.Website, len(result.APIErrors))
+			failures := make([]notify.FailedRequest, 0, len(result.APIErrors))
+			if len(result.APIErrors) > 0 {
+				body += "\nFailed API Calls:\n"
+			}
+			for _, apiErr := range result.APIErrors {
+				body += fmt.Sprintf("  %d %s\n", apiErr.StatusCode, apiErr.URL)
+				failures = append(failures, notify.FailedRequest{URL: apiErr.URL, StatusCode: apiErr.StatusCode})
+			}
 
-				for _, apiErr := range result.APIErrors {
-					body += fmt.Sprintf("  %d %s\n", apiErr.StatusCode, apiErr.URL)
+			if len(steps) > 0 {
+				body += "\nFailed Steps (screenshot and DOM saved as artifacts):\n"
+				for _, step := range steps {
+					if step.ArtifactID != "" {
+						body += fmt.Sprintf("  %s [artifact %s]\n", step.Description, step.ArtifactID)
+					} else {
+						body += fmt.Sprintf("  %s\n", step.Description)
+					}
 				}
+			}
+
+			subject := fmt.Sprintf("⚠️ Snapshot Replay - API Errors Detected for %s", job.Website)
+			if err != nil {
+				subject = fmt.Sprintf("❌ Snapshot Replay - Failed for %s", job.Website)
+			} else if len(steps) > 0 {
+				subject = fmt.Sprintf("⚠️ Snapshot Replay - Steps Failed for %s", job.Website)
+			}
 
-				subject := fmt.Sprintf("⚠️ Snapshot Replay - API Errors Detected for %s", job.Website)
-				sendErrorAlert("snapshot_"+snap.ID, job.Email, subject, body, alertLog, logger)
+			snapshotAlert := notify.Alert{
+				Key:             "snapshot_" + snap.ID,
+				Kind:            notify.KindError,
+				Website:         job.Website,
+				Subject:         subject,
+				Body:            body,
+				Failures:        failures,
+				SnapshotID:      snap.ID,
+				SnapshotVersion: snap.Version,
+				FailedSteps:     steps,
+				Timestamp:       time.Now(),
 			}
+			// Replays aren't retried, so a single failed replay meets the failure threshold
+			snapshotPolicy := config.AlertPolicy{}
+			if job.Policy != nil {
+				snapshotPolicy = *job.Policy
+			}
+			snapshotPolicy.FailureThreshold = 0
+			sendErrorAlert(ctx, snapshotAlert, job.Email, &snapshotPolicy, 1, job.Silences, job.Incidents, alertLog, logger)
 		} else {
 			// Successful replay with no API errors
 			logger.Logf("[SNAPSHOT] ✅ Replay COMPLETED in %v for %s (ID: %s)",
 				result.Duration, job.Website, snap.ID)
+			resolveIncident(job.Incidents, "snapshot_"+snap.ID, logger)
 		}
 	}
 
 	logger.Logf("[SNAPSHOTS] All snapshots completed for %s", job.Website)
+	return runs
+}
+
+// failedSteps lists the failed required steps of a replay with their captured
+// artifacts, starting with the initial navigation if that failed
+func failedSteps(result *snapshot.ReplayResult) []notify.FailedStep {
+	failed := result.FailedSteps()
+	steps := make([]notify.FailedStep, 0, len(failed)+1)
+	for _, f := range result.Failures {
+		if f.ActionIndex < 0 {
+			steps = append(steps, notify.FailedStep{Description: f.Describe(), ArtifactID: f.ID})
+		}
+	}
+	for _, step := range failed {
+		steps = append(steps, notify.FailedStep{Description: step.Describe(), ArtifactID: step.ArtifactID})
+	}
+	return steps
 }
 
-// sendErrorAlert sends an email alert for API errors with throttling to prevent email floods
-// alertKey is used to track when the last alert was sent (can be website name or "snapshot_" + snapshotID)
-func sendErrorAlert(alertKey string, recipientEmail string, subject string, body string, alertLog alert.Log, logger Logger) bool {
-	now := time.Now().Unix()
-	fiveHours := int64(5 * 3600) // 5 hours in seconds
+// finishReplaySpan records the outcome of a snapshot replay and ends its span
+func finishReplaySpan(span *tracing.Span, run SnapshotRun) {
+	outcome := run.Outcome()
+	span.SetAttribute("apiwatcher.outcome", outcome)
+	switch {
+	case run.Error != nil:
+		span.SetError(run.Error.Error())
+	case outcome == SnapshotStepsFailed:
+		failed := len(run.Result.FailedSteps())
+		span.SetAttribute("apiwatcher.failed_steps", failed)
+		span.SetError(fmt.Sprintf("%d of %d step(s) failed", failed, len(run.Result.Steps)))
+	case outcome == SnapshotAPIErrors:
+		span.SetAttribute("apiwatcher.error_count", len(run.Result.APIErrors))
+		span.SetError(fmt.Sprintf("%d API error(s)", len(run.Result.APIErrors)))
+	}
+	span.Finish()
+}
 
-	// Check if we've sent an alert for this key recently
-	if lastAlert, exists := alertLog[alertKey]; exists && now-lastAlert < fiveHours {
-		logger.Logf("[INFO] Skipping email for %s (sent recently)", alertKey)
+// finishSnapshotsSpan records the outcomes of all replays of a website and ends its span
+func finishSnapshotsSpan(span *tracing.Span, runs []SnapshotRun) {
+	failed := 0
+	for _, run := range runs {
+		if run.Outcome() != SnapshotPassed {
+			failed++
+		}
+	}
+	span.SetAttribute("apiwatcher.replays_failed", failed)
+	if failed > 0 {
+		span.SetError(fmt.Sprintf("%d of %d replay(s) failed", failed, len(runs)))
+	}
+	span.Finish()
+}
+
+// sendErrorAlert sends an alert to every configured channel (email and webhooks)
+// according to the alert policy, so alert floods are throttled while ongoing incidents
+// are repeated and escalated. Silenced websites never alert. The alert key tracks the incident and when the last alert
+// was sent (can be website name or "snapshot_" + snapshotID). failures is the number of
+// consecutive failures including the current one.
+func sendErrorAlert(ctx context.Context, a notify.Alert, recipientEmail string, policy *config.AlertPolicy, failures int, silences *alert.SilenceStore, incidents *alert.IncidentStore, alertLog alert.Log, logger Logger) bool {
+	if silence := silences.Active(a.Website); silence != nil {
+		logger.Logf("[INFO] Skipping alert for %s (silenced until %s: %s)",
+			a.Key, silence.ExpiresAt.Format("2006-01-02 15:04:05"), silence.Reason)
+		return false
+	}
+
+	now := time.Now()
+	var lastAlert time.Time
+	if sentAt, exists := alertLog[a.Key]; exists {
+		lastAlert = time.Unix(sentAt, 0)
+	}
+
+	decision, err := incidents.Evaluate(a.Key, policy, failures, lastAlert, now)
+	if err != nil {
+		// Fall back to the plain cooldown if incidents can't be tracked
+		if incidents != nil {
+			logger.Logf("[ERROR] Failed to evaluate alert policy: %v", err)
+		}
+		decision = alert.Decision{Notify: lastAlert.IsZero() || now.Sub(lastAlert) >= policy.Cooldown(), Reason: "sent recently"}
+	}
+	if !decision.Notify && !decision.Escalate {
+		logger.Logf("[INFO] Skipping alert for %s (%s)", a.Key, decision.Reason)
 		return false
 	}
 
-	// Send email
-	if err := email.Send(recipientEmail, subject, body); err != nil {
-		logger.Logf("[ERROR] Failed to send email: %v", err)
+	if decision.Notify {
+		notice := a
+		if decision.Repeat {
+			notice.Subject = "🔁 Still failing - " + a.Subject
+		}
+		decision.Notify = dispatchAlert(ctx, notice, recipientEmail, logger)
+	}
+
+	if decision.Escalate {
+		escalated := a
+		escalated.Subject = "🚨 Escalated - " + a.Subject
+		escalationEmail := strings.Join(policy.EscalationEmails, ",")
+		if err := (&notify.EmailNotifier{To: escalationEmail}).Notify(ctx, escalated); err != nil {
+			logger.Logf("[ERROR] Failed to escalate alert for %s: %v", a.Key, err)
+			decision.Escalate = false
+		} else {
+			logger.Logf("[ALERT] Escalated %s to %s", a.Key, escalationEmail)
+		}
+	}
+
+	if !decision.Notify && !decision.Escalate {
 		return false
 	}
 
+	if err := incidents.RecordSent(a.Key, decision, now); err != nil && incidents != nil {
+		logger.Logf("[ERROR] Failed to save incident: %v", err)
+	}
+
 	// Update alert log
-	alertLog[alertKey] = now
+	alertLog[a.Key] = now.Unix()
 	if err := alert.SaveLog(alertLog); err != nil {
 		logger.Logf("[ERROR] Failed to save alert log: %v", err)
 		return false
 	}
 
-	logger.Logf("[ALERT] Email sent successfully for %s to %s", alertKey, recipientEmail)
+	return true
+}
+
+// resolveIncident closes the open incident for an alert key after a successful check
+func resolveIncident(incidents *alert.IncidentStore, key string, logger Logger) {
+	incident, err := incidents.Resolve(key)
+	if err != nil {
+		logger.Logf("[ERROR] Failed to resolve incident for %s: %v", key, err)
+		return
+	}
+	if incident != nil {
+		logger.Logf("[INFO] Incident for %s closed after %v", key, time.Since(incident.OpenedAt).Round(time.Second))
+	}
+}
+
+// SendResolvedAlert tells every configured channel that a failing website is back up
+func SendResolvedAlert(ctx context.Context, website string, recipientEmail string, firstFailure, lastFailure time.Time, downtime time.Duration, logger Logger) bool {
+	body := fmt.Sprintf(`Website Recovered
+
+Website: %s
+
+First failure: %s
+Last failure: %s
+Downtime: %s
+`, website, firstFailure.Format("2006-01-02 15:04:05"), lastFailure.Format("2006-01-02 15:04:05"), downtime.Round(time.Second))
+
+	resolved := notify.Alert{
+		Key:             website,
+		Kind:            notify.KindResolved,
+		Website:         website,
+		Subject:         fmt.Sprintf("✅ Resolved - %s is back up", website),
+		Body:            body,
+		Timestamp:       time.Now(),
+		DowntimeSeconds: int64(downtime.Round(time.Second) / time.Second),
+		FirstFailureAt:  firstFailure,
+		LastFailureAt:   lastFailure,
+	}
+	return dispatchAlert(ctx, resolved, recipientEmail, logger)
+}
+
+// alertDeliveryTimeout bounds how long a check waits for its alert channels,
+// webhook retries included
+const alertDeliveryTimeout = time.Minute
+
+// dispatchAlert delivers an alert to every configured channel in parallel and waits
+// for them, returning true if any succeeded. Delivery (webhook retries included) is
+// bounded by alertDeliveryTimeout and stops when ctx is cancelled.
+func dispatchAlert(ctx context.Context, a notify.Alert, recipientEmail string, logger Logger) bool {
+	notifiers, err := notify.Configured(recipientEmail)
+	if err != nil {
+		logger.Logf("[ERROR] %v", err)
+	}
+	if len(notifiers) == 0 {
+		logger.Logf("[INFO] No alert channels configured for %s", a.Key)
+		return false
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, alertDeliveryTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var sent atomic.Bool
+	for _, n := range notifiers {
+		wg.Go(func() {
+			if deliverAlert(ctx, n, a, logger) {
+				sent.Store(true)
+			}
+		})
+	}
+	wg.Wait()
+	return sent.Load()
+}
+
+// deliverAlert sends an alert through one channel and logs the outcome
+func deliverAlert(ctx context.Context, n notify.Notifier, a notify.Alert, logger Logger) bool {
+	if err := n.Notify(ctx, a); err != nil {
+		logger.Logf("[ERROR] Failed to send alert via %s: %v", n.Name(), err)
+		return false
+	}
+	logger.Logf("[ALERT] Alert sent successfully for %s via %s", a.Key, n.Name())
 	return true
 }
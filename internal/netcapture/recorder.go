@@ -0,0 +1,308 @@
+package netcapture
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"apiwatcher/internal/models"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// maxCapturedBody caps the size of request and response bodies kept per request
+const maxCapturedBody = 1024 * 1024
+
+// Recorder builds a models.APIRequest for every request of a browser session
+// from chromedp network events. Feed it every event with Handle.
+type Recorder struct {
+	mutex    sync.Mutex
+	requests []*entry
+	byID     map[network.RequestID]*entry // Latest entry of each request ID (redirects reuse IDs)
+}
+
+type entry struct {
+	id       network.RequestID
+	request  models.APIRequest
+	sentAt   time.Time // Browser monotonic clock
+	finished bool
+}
+
+// NewRecorder creates an empty recorder
+func NewRecorder() *Recorder {
+	return &Recorder{byID: make(map[network.RequestID]*entry)}
+}
+
+// Handle updates the captured requests from a chromedp event; other events are ignored
+func (r *Recorder) Handle(ev interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch ev := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		r.requestSent(ev)
+	case *network.EventResponseReceived:
+		if e := r.byID[ev.RequestID]; e != nil && ev.Response != nil {
+			e.applyResponse(ev.Response, ev.Type)
+		}
+	case *network.EventDataReceived:
+		if e := r.byID[ev.RequestID]; e != nil {
+			e.request.BodySize += ev.DataLength
+		}
+	case *network.EventLoadingFinished:
+		if e := r.byID[ev.RequestID]; e != nil {
+			e.request.TransferSize = int64(ev.EncodedDataLength)
+			e.finish(monotonic(ev.Timestamp))
+		}
+	case *network.EventLoadingFailed:
+		if e := r.byID[ev.RequestID]; e != nil {
+			e.request.Error = ev.ErrorText
+			if ev.BlockedReason != "" {
+				e.request.Error = fmt.Sprintf("%s (%s)", ev.ErrorText, ev.BlockedReason)
+			}
+			if e.request.ResourceType == "" {
+				e.request.ResourceType = string(ev.Type)
+			}
+			e.finish(monotonic(ev.Timestamp))
+		}
+	}
+}
+
+// requestSent starts a new entry. A redirect completes the previous entry of the
+// same request ID with the redirect response. Caller must hold the lock.
+func (r *Recorder) requestSent(ev *network.EventRequestWillBeSent) {
+	if ev.Request == nil {
+		return
+	}
+	sentAt := monotonic(ev.Timestamp)
+
+	if prev := r.byID[ev.RequestID]; prev != nil && ev.RedirectResponse != nil {
+		prev.applyResponse(ev.RedirectResponse, ev.Type)
+		prev.request.TransferSize = int64(ev.RedirectResponse.EncodedDataLength)
+		prev.finish(sentAt)
+	}
+
+	startedAt := time.Now()
+	if ev.WallTime != nil {
+		startedAt = ev.WallTime.Time()
+	}
+
+	body, size := postData(ev.Request)
+	e := &entry{
+		id:     ev.RequestID,
+		sentAt: sentAt,
+		request: models.APIRequest{
+			URL:          ev.Request.URL,
+			Method:       ev.Request.Method,
+			ReqHeaders:   HeaderStrings(ev.Request.Headers),
+			Timestamp:    startedAt,
+			StartedAt:    startedAt,
+			ResourceType: string(ev.Type),
+			RequestSize:  size,
+			RequestBody:  body,
+		},
+	}
+	r.requests = append(r.requests, e)
+	r.byID[ev.RequestID] = e
+}
+
+// LoadBodies fetches the bodies of finished text responses (HTML, JSON, scripts, ...)
+// from the browser. ctx must be the chromedp context of the recorded session.
+// Bodies the browser no longer holds are skipped.
+func (r *Recorder) LoadBodies(ctx context.Context) {
+	r.mutex.Lock()
+	var pending []*entry
+	for _, e := range r.requests {
+		if e.finished && e.request.Error == "" && e.request.Body == "" && isText(e.request.MIMEType) &&
+			e.request.BodySize <= maxCapturedBody {
+			pending = append(pending, e)
+		}
+	}
+	r.mutex.Unlock()
+
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+	executor := cdp.WithExecutor(ctx, c.Target)
+	for _, e := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		body, err := network.GetResponseBody(e.id).Do(executor)
+		if err != nil {
+			continue
+		}
+		r.mutex.Lock()
+		e.request.Body = string(body)
+		r.mutex.Unlock()
+	}
+}
+
+// Get returns a copy of the latest request with the given ID, or nil
+func (r *Recorder) Get(id network.RequestID) *models.APIRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	e := r.byID[id]
+	if e == nil {
+		return nil
+	}
+	return e.copy()
+}
+
+// Requests returns copies of all captured requests in the order they were sent
+func (r *Recorder) Requests() []*models.APIRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	requests := make([]*models.APIRequest, 0, len(r.requests))
+	for _, e := range r.requests {
+		requests = append(requests, e.copy())
+	}
+	return requests
+}
+
+// isText reports whether a MIME type is worth keeping as text
+func isText(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if strings.HasPrefix(mimeType, "text/") {
+		return true
+	}
+	for _, kind := range []string{"json", "javascript", "xml", "x-www-form-urlencoded", "graphql"} {
+		if strings.Contains(mimeType, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// ==========================
+// Entry Updates
+// ==========================
+
+// copy returns a copy of the request that later events won't modify
+func (e *entry) copy() *models.APIRequest {
+	req := e.request
+	if req.Timing != nil {
+		timing := *req.Timing
+		req.Timing = &timing
+	}
+	return &req
+}
+
+func (e *entry) applyResponse(resp *network.Response, resourceType network.ResourceType) {
+	e.request.URL = resp.URL
+	e.request.StatusCode = int(resp.Status)
+	e.request.StatusText = resp.StatusText
+	e.request.RespHeaders = HeaderStrings(resp.Headers)
+	e.request.Protocol = resp.Protocol
+	e.request.MIMEType = resp.MimeType
+	if resourceType != "" {
+		e.request.ResourceType = string(resourceType)
+	}
+	// Refined headers include the ones Chrome adds itself (cookies, etc.)
+	if len(resp.RequestHeaders) > 0 {
+		e.request.ReqHeaders = HeaderStrings(resp.RequestHeaders)
+	}
+	if resp.Timing != nil {
+		e.request.Timing = buildTiming(resp.Timing, e.sentAt)
+	}
+}
+
+// finish completes the timing once the last byte arrived (or the request failed)
+func (e *entry) finish(at time.Time) {
+	if e.finished {
+		return
+	}
+	e.finished = true
+	if e.request.Timing == nil || at.IsZero() || e.sentAt.IsZero() {
+		return
+	}
+
+	total := milliseconds(at.Sub(e.sentAt))
+	headersEnd := e.request.Timing.Total - e.request.Timing.Receive
+	if total > headersEnd {
+		e.request.Timing.Receive = total - headersEnd
+		e.request.Timing.Total = total
+	}
+}
+
+// buildTiming converts Chrome's resource timing into phase durations. Until the
+// response body arrives, Total ends when the response headers were received.
+func buildTiming(t *network.ResourceTiming, sentAt time.Time) *models.RequestTiming {
+	// Time between the request being issued and Chrome starting to process it
+	queued := 0.0
+	if !sentAt.IsZero() {
+		requestTime := cdp.MonotonicTimeEpoch.Add(time.Duration(t.RequestTime * float64(time.Second)))
+		queued = max(0, milliseconds(requestTime.Sub(sentAt)))
+	}
+
+	timing := &models.RequestTiming{
+		DNS:     phase(t.DNSStart, t.DNSEnd),
+		Connect: phase(t.ConnectStart, t.ConnectEnd),
+		TLS:     phase(t.SslStart, t.SslEnd),
+		Send:    max(0, phase(t.SendStart, t.SendEnd)),
+		Wait:    max(0, phase(t.SendEnd, t.ReceiveHeadersEnd)),
+		Total:   queued + max(0, t.ReceiveHeadersEnd),
+	}
+
+	timing.Blocked = queued
+	for _, start := range []float64{t.DNSStart, t.ConnectStart, t.SendStart} {
+		if start >= 0 {
+			timing.Blocked += start
+			break
+		}
+	}
+	return timing
+}
+
+// phase returns end-start, or -1 if the phase didn't happen
+func phase(start, end float64) float64 {
+	if start < 0 || end < start {
+		return -1
+	}
+	return end - start
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func monotonic(t *cdp.MonotonicTime) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time()
+}
+
+// postData returns the (possibly truncated) body of a request and its size in bytes
+func postData(req *network.Request) (string, int64) {
+	var body strings.Builder
+	var size int64
+	for _, part := range req.PostDataEntries {
+		decoded, err := base64.StdEncoding.DecodeString(part.Bytes)
+		if err != nil {
+			decoded = []byte(part.Bytes)
+		}
+		size += int64(len(decoded))
+		if remaining := maxCapturedBody - body.Len(); remaining > 0 {
+			body.Write(decoded[:min(len(decoded), remaining)])
+		}
+	}
+	return body.String(), size
+}
+
+// HeaderStrings converts CDP headers into a plain string map
+func HeaderStrings(headers network.Headers) map[string]string {
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		result[k] = fmt.Sprintf("%v", v)
+	}
+	return result
+}
@@ -0,0 +1,287 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxListedFailures caps the failing calls listed in chat messages
+const maxListedFailures = 10
+
+// ==========================
+// Shared Helpers
+// ==========================
+
+// summaryLine returns a one line summary of the alert, with the site rendered by link
+// and every other value by escape
+func summaryLine(a Alert, link, escape func(string) string) string {
+	site := link(a.Website)
+	count := len(a.Failures) + len(a.AssertionFailures)
+	switch {
+	case a.Kind == KindResolved:
+		return fmt.Sprintf("%s is back up after %s", site, a.Downtime())
+	case a.SnapshotID != "":
+		label := escape(a.SnapshotID)
+		if a.SnapshotVersion > 0 {
+			label += fmt.Sprintf(" (v%d)", a.SnapshotVersion)
+		}
+		summary := fmt.Sprintf("Snapshot %s on %s: ", label, site)
+		if len(a.FailedSteps) == 0 {
+			return summary + fmt.Sprintf("%d failing API call(s)", len(a.Failures))
+		}
+		summary += fmt.Sprintf("%d failed step(s)", len(a.FailedSteps))
+		if len(a.Failures) > 0 {
+			summary += fmt.Sprintf(", %d failing API call(s)", len(a.Failures))
+		}
+		return summary
+	case count > 0:
+		return fmt.Sprintf("%s: %d failing API call(s), %d failed assertion(s)", site, len(a.Failures), len(a.AssertionFailures))
+	default:
+		return site
+	}
+}
+
+// isWebURL reports whether s is an absolute http(s) URL, the only kind worth linking
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// failureLines lists failing calls as "STATUS URL", truncated to maxListedFailures
+func failureLines(a Alert) []string {
+	lines := make([]string, 0, len(a.Failures))
+	for i, f := range a.Failures {
+		if i == maxListedFailures {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(a.Failures)-maxListedFailures))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%d %s", f.StatusCode, f.URL))
+	}
+	return lines
+}
+
+// stepLines lists failed replay steps with the artifact captured at each
+func stepLines(a Alert) []string {
+	lines := make([]string, 0, len(a.FailedSteps))
+	for _, step := range a.FailedSteps {
+		lines = append(lines, fmt.Sprintf("%s (artifact %s)", step.Description, step.ArtifactID))
+	}
+	return lines
+}
+
+// outageLines describes the outage window of a resolved alert
+func outageLines(a Alert) []string {
+	if a.Kind != KindResolved {
+		return nil
+	}
+	return []string{
+		"First failure: " + a.FirstFailureAt.Format("2006-01-02 15:04:05"),
+		"Last failure: " + a.LastFailureAt.Format("2006-01-02 15:04:05"),
+		"Downtime: " + a.Downtime().String(),
+	}
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// ==========================
+// Slack (Block Kit)
+// ==========================
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"` // Fallback for notifications
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackEscaper escapes the characters Slack mrkdwn reserves for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackEscape escapes text for a Slack mrkdwn field
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+// slackLink renders a web URL as a Slack mrkdwn link, anything else as plain text
+func slackLink(s string) string {
+	if !isWebURL(s) {
+		return slackEscape(s)
+	}
+	return fmt.Sprintf("<%s|%s>", slackEscape(s), slackEscape(s))
+}
+
+// slackList escapes each line of a Slack list
+func slackList(lines []string) []string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = slackEscape(line)
+	}
+	return escaped
+}
+
+// SlackPayload builds a Slack incoming webhook message for the alert
+func SlackPayload(a Alert) ([]byte, error) {
+	msg := slackMessage{
+		Text: slackEscape(a.Subject + " - " + a.Website),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(a.Subject, 150)}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: summaryLine(a, slackLink, slackEscape)}},
+		},
+	}
+
+	if lines := outageLines(a); len(lines) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")},
+		})
+	}
+
+	if lines := slackList(failureLines(a)); len(lines) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncate("*Failed API calls*\n```"+strings.Join(lines, "\n")+"```", 3000)},
+		})
+	}
+
+	if len(a.AssertionFailures) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncate("*Failed assertions*\n• "+strings.Join(slackList(a.AssertionFailures), "\n• "), 3000)},
+		})
+	}
+
+	if lines := slackList(stepLines(a)); len(lines) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncate("*Failed steps*\n• "+strings.Join(lines, "\n• "), 3000)},
+		})
+	}
+
+	msg.Blocks = append(msg.Blocks, slackBlock{
+		Type:     "context",
+		Elements: []slackText{{Type: "mrkdwn", Text: "ApiWatcher • " + a.Timestamp.Format("2006-01-02 15:04:05")}},
+	})
+
+	return json.Marshal(msg)
+}
+
+// ==========================
+// Microsoft Teams (MessageCard)
+// ==========================
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsSection struct {
+	ActivityTitle    string      `json:"activityTitle,omitempty"`
+	ActivitySubtitle string      `json:"activitySubtitle,omitempty"`
+	Text             string      `json:"text,omitempty"`
+	Facts            []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+type teamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []teamsTarget `json:"targets"`
+}
+
+type teamsCard struct {
+	Type            string         `json:"@type"`
+	Context         string         `json:"@context"`
+	ThemeColor      string         `json:"themeColor"`
+	Summary         string         `json:"summary"`
+	Title           string         `json:"title"`
+	Sections        []teamsSection `json:"sections"`
+	PotentialAction []teamsAction  `json:"potentialAction,omitempty"`
+}
+
+// teamsLink renders a web URL as a Markdown link, anything else as plain text
+func teamsLink(s string) string {
+	if !isWebURL(s) {
+		return s
+	}
+	return fmt.Sprintf("[%s](%s)", s, s)
+}
+
+// TeamsPayload builds a Microsoft Teams MessageCard for the alert
+func TeamsPayload(a Alert) ([]byte, error) {
+	section := teamsSection{
+		ActivityTitle:    summaryLine(a, teamsLink, func(s string) string { return s }),
+		ActivitySubtitle: a.Timestamp.Format("2006-01-02 15:04:05"),
+	}
+	if a.Kind == KindResolved {
+		section.Facts = append(section.Facts,
+			teamsFact{Name: "First failure", Value: a.FirstFailureAt.Format("2006-01-02 15:04:05")},
+			teamsFact{Name: "Last failure", Value: a.LastFailureAt.Format("2006-01-02 15:04:05")},
+			teamsFact{Name: "Downtime", Value: a.Downtime().String()},
+		)
+	}
+	for i, f := range a.Failures {
+		if i == maxListedFailures {
+			section.Facts = append(section.Facts, teamsFact{Name: "…", Value: fmt.Sprintf("and %d more", len(a.Failures)-maxListedFailures)})
+			break
+		}
+		section.Facts = append(section.Facts, teamsFact{Name: fmt.Sprintf("%d", f.StatusCode), Value: f.URL})
+	}
+	if len(a.AssertionFailures) > 0 {
+		section.Text = "**Failed assertions**\n\n- " + strings.Join(a.AssertionFailures, "\n- ")
+	}
+	if lines := stepLines(a); len(lines) > 0 {
+		if section.Text != "" {
+			section.Text += "\n\n"
+		}
+		section.Text += "**Failed steps**\n\n- " + strings.Join(lines, "\n- ")
+	}
+
+	card := teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: themeColor(a.Kind),
+		Summary:    a.Subject,
+		Title:      a.Subject,
+		Sections:   []teamsSection{section},
+	}
+	if isWebURL(a.Website) {
+		card.PotentialAction = []teamsAction{{
+			Type:    "OpenUri",
+			Name:    "Open site",
+			Targets: []teamsTarget{{OS: "default", URI: a.Website}},
+		}}
+	}
+
+	return json.Marshal(card)
+}
+
+// themeColor returns the card accent color for an alert kind
+func themeColor(kind string) string {
+	if kind == KindResolved {
+		return "2EB886"
+	}
+	return "D70000"
+}
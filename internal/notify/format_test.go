@@ -0,0 +1,261 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	testTime     = time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC)
+	firstFailure = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+)
+
+// formatCases are the alerts both payload builders are checked with
+var formatCases = map[string]Alert{
+	"check failure": {
+		Kind:              KindError,
+		Website:           "https://shop.example.com",
+		Subject:           "Website Down",
+		Failures:          []FailedRequest{{URL: "https://shop.example.com/api/cart", StatusCode: 500}},
+		AssertionFailures: []string{`body matches "<ok>" & status 200`},
+		Timestamp:         testTime,
+	},
+	"snapshot steps": {
+		Kind:            KindError,
+		Website:         "shop",
+		Subject:         "Steps Failed",
+		SnapshotID:      "checkout",
+		SnapshotVersion: 3,
+		FailedSteps:     []FailedStep{{Description: "Step 2 (click form > #pay): timeout", ArtifactID: "a1"}},
+		Timestamp:       testTime,
+	},
+	"resolved": {
+		Kind:            KindResolved,
+		Website:         "https://shop.example.com",
+		Subject:         "Website Recovered",
+		DowntimeSeconds: 1800,
+		FirstFailureAt:  firstFailure,
+		LastFailureAt:   testTime,
+		Timestamp:       testTime,
+	},
+	"many failures": {
+		Kind:      KindError,
+		Website:   "api",
+		Subject:   "API Errors",
+		Failures:  manyFailures(maxListedFailures + 2),
+		Timestamp: testTime,
+	},
+}
+
+func manyFailures(n int) []FailedRequest {
+	failures := make([]FailedRequest, n)
+	for i := range failures {
+		failures[i] = FailedRequest{URL: fmt.Sprintf("/api/%d", i), StatusCode: 503}
+	}
+	return failures
+}
+
+func TestSlackPayload(t *testing.T) {
+	var listed string
+	for i := range maxListedFailures {
+		listed += fmt.Sprintf("503 /api/%d\n", i)
+	}
+
+	tests := map[string]string{
+		"check failure": `{
+			"text": "Website Down - https://shop.example.com",
+			"blocks": [
+				{"type": "header", "text": {"type": "plain_text", "text": "Website Down"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "<https://shop.example.com|https://shop.example.com>: 1 failing API call(s), 1 failed assertion(s)"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "*Failed API calls*\n` + "```500 https://shop.example.com/api/cart```" + `"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "*Failed assertions*\n• body matches \"&lt;ok&gt;\" &amp; status 200"}},
+				{"type": "context", "elements": [{"type": "mrkdwn", "text": "ApiWatcher • 2025-06-01 09:30:00"}]}
+			]
+		}`,
+		"snapshot steps": `{
+			"text": "Steps Failed - shop",
+			"blocks": [
+				{"type": "header", "text": {"type": "plain_text", "text": "Steps Failed"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "Snapshot checkout (v3) on shop: 1 failed step(s)"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "*Failed steps*\n• Step 2 (click form &gt; #pay): timeout (artifact a1)"}},
+				{"type": "context", "elements": [{"type": "mrkdwn", "text": "ApiWatcher • 2025-06-01 09:30:00"}]}
+			]
+		}`,
+		"resolved": `{
+			"text": "Website Recovered - https://shop.example.com",
+			"blocks": [
+				{"type": "header", "text": {"type": "plain_text", "text": "Website Recovered"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "<https://shop.example.com|https://shop.example.com> is back up after 30m0s"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "First failure: 2025-06-01 09:00:00\nLast failure: 2025-06-01 09:30:00\nDowntime: 30m0s"}},
+				{"type": "context", "elements": [{"type": "mrkdwn", "text": "ApiWatcher • 2025-06-01 09:30:00"}]}
+			]
+		}`,
+		"many failures": `{
+			"text": "API Errors - api",
+			"blocks": [
+				{"type": "header", "text": {"type": "plain_text", "text": "API Errors"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "api: 12 failing API call(s), 0 failed assertion(s)"}},
+				{"type": "section", "text": {"type": "mrkdwn", "text": ` + jsonString("*Failed API calls*\n```"+listed+"…and 2 more```") + `}},
+				{"type": "context", "elements": [{"type": "mrkdwn", "text": "ApiWatcher • 2025-06-01 09:30:00"}]}
+			]
+		}`,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := SlackPayload(formatCases[name])
+			if err != nil {
+				t.Fatalf("SlackPayload: %v", err)
+			}
+			assertJSON(t, got, want)
+		})
+	}
+}
+
+func TestTeamsPayload(t *testing.T) {
+	var listed string
+	for i := range maxListedFailures {
+		listed += fmt.Sprintf(`{"name": "503", "value": "/api/%d"},`, i)
+	}
+
+	tests := map[string]string{
+		"check failure": `{
+			"@type": "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"themeColor": "D70000",
+			"summary": "Website Down",
+			"title": "Website Down",
+			"sections": [{
+				"activityTitle": "[https://shop.example.com](https://shop.example.com): 1 failing API call(s), 1 failed assertion(s)",
+				"activitySubtitle": "2025-06-01 09:30:00",
+				"text": "**Failed assertions**\n\n- body matches \"<ok>\" & status 200",
+				"facts": [{"name": "500", "value": "https://shop.example.com/api/cart"}]
+			}],
+			"potentialAction": [{"@type": "OpenUri", "name": "Open site", "targets": [{"os": "default", "uri": "https://shop.example.com"}]}]
+		}`,
+		"snapshot steps": `{
+			"@type": "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"themeColor": "D70000",
+			"summary": "Steps Failed",
+			"title": "Steps Failed",
+			"sections": [{
+				"activityTitle": "Snapshot checkout (v3) on shop: 1 failed step(s)",
+				"activitySubtitle": "2025-06-01 09:30:00",
+				"text": "**Failed steps**\n\n- Step 2 (click form > #pay): timeout (artifact a1)"
+			}]
+		}`,
+		"resolved": `{
+			"@type": "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"themeColor": "2EB886",
+			"summary": "Website Recovered",
+			"title": "Website Recovered",
+			"sections": [{
+				"activityTitle": "[https://shop.example.com](https://shop.example.com) is back up after 30m0s",
+				"activitySubtitle": "2025-06-01 09:30:00",
+				"facts": [
+					{"name": "First failure", "value": "2025-06-01 09:00:00"},
+					{"name": "Last failure", "value": "2025-06-01 09:30:00"},
+					{"name": "Downtime", "value": "30m0s"}
+				]
+			}],
+			"potentialAction": [{"@type": "OpenUri", "name": "Open site", "targets": [{"os": "default", "uri": "https://shop.example.com"}]}]
+		}`,
+		"many failures": `{
+			"@type": "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"themeColor": "D70000",
+			"summary": "API Errors",
+			"title": "API Errors",
+			"sections": [{
+				"activityTitle": "api: 12 failing API call(s), 0 failed assertion(s)",
+				"activitySubtitle": "2025-06-01 09:30:00",
+				"facts": [` + listed + `{"name": "…", "value": "and 2 more"}]
+			}]
+		}`,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := TeamsPayload(formatCases[name])
+			if err != nil {
+				t.Fatalf("TeamsPayload: %v", err)
+			}
+			assertJSON(t, got, want)
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		in    string
+		slack string
+		teams string
+	}{
+		{"https://shop.example.com", "<https://shop.example.com|https://shop.example.com>", "[https://shop.example.com](https://shop.example.com)"},
+		{"http://shop.example.com/?a=1&b=2", "<http://shop.example.com/?a=1&amp;b=2|http://shop.example.com/?a=1&amp;b=2>", "[http://shop.example.com/?a=1&b=2](http://shop.example.com/?a=1&b=2)"},
+		{"shop", "shop", "shop"},
+		{"/api/cart", "/api/cart", "/api/cart"},
+		{"javascript:alert(1)", "javascript:alert(1)", "javascript:alert(1)"},
+		{"<!channel>", "&lt;!channel&gt;", "<!channel>"},
+	}
+	for _, tt := range tests {
+		if got := slackLink(tt.in); got != tt.slack {
+			t.Errorf("slackLink(%q) = %q, want %q", tt.in, got, tt.slack)
+		}
+		if got := teamsLink(tt.in); got != tt.teams {
+			t.Errorf("teamsLink(%q) = %q, want %q", tt.in, got, tt.teams)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long", 5, "too …"},
+		{"ééééé", 3, "éé…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+// assertJSON compares a rendered payload with the expected JSON, ignoring formatting
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("payload isn't valid JSON: %v\n%s", err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("expected JSON is invalid: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("payload mismatch\ngot:  %s\nwant: %s", got, compact(want))
+	}
+}
+
+func compact(s string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"apiwatcher/internal/config"
+	"apiwatcher/internal/email"
+)
+
+// Alert kinds
+const (
+	KindError    = "error"    // A check or snapshot replay failed
+	KindResolved = "resolved" // A failing website is back up
+)
+
+// FailedRequest is a single failing API call included in an alert
+type FailedRequest struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// FailedStep is a snapshot replay step that failed, with the page state captured at that step
+type FailedStep struct {
+	Description string `json:"description"`
+	ArtifactID  string `json:"artifact_id"` // Screenshot and DOM (GET_ARTIFACT)
+}
+
+// Alert is a channel independent alert passed to every notifier
+type Alert struct {
+	Key               string          `json:"key"`  // Throttling key (website or "snapshot_" + ID)
+	Kind              string          `json:"kind"` // KindError or KindResolved
+	Website           string          `json:"website"`
+	Subject           string          `json:"subject"`
+	Body              string          `json:"body"` // Plain text body (used for email)
+	Failures          []FailedRequest `json:"failures,omitempty"`
+	AssertionFailures []string        `json:"assertion_failures,omitempty"`
+	SnapshotID        string          `json:"snapshot_id,omitempty"`
+	SnapshotVersion   int             `json:"snapshot_version,omitempty"` // Version that ran (snapshot replays only)
+	FailedSteps       []FailedStep    `json:"failed_steps,omitempty"`     // Snapshot replays only
+	Timestamp         time.Time       `json:"timestamp"`
+
+	// Outage details (resolved alerts only)
+	DowntimeSeconds int64     `json:"downtime_seconds,omitempty"`
+	FirstFailureAt  time.Time `json:"first_failure_at,omitzero"`
+	LastFailureAt   time.Time `json:"last_failure_at,omitzero"`
+}
+
+// Downtime returns the outage duration of a resolved alert
+func (a Alert) Downtime() time.Duration {
+	return time.Duration(a.DowntimeSeconds) * time.Second
+}
+
+// Notifier delivers alerts to a single channel
+type Notifier interface {
+	// Name identifies the channel in logs, e.g. "email" or "webhook:oncall"
+	Name() string
+	// Notify delivers the alert, returning an error if delivery failed
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// ==========================
+// Email Channel
+// ==========================
+
+// EmailNotifier sends alerts through the configured SMTP server
+type EmailNotifier struct {
+	To string // Comma separated recipients
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alert Alert) error {
+	return email.Send(n.To, alert.Subject, alert.Body)
+}
+
+// ==========================
+// Channel Discovery
+// ==========================
+
+// Configured returns a notifier for every configured alert channel:
+// email (when a recipient is given) and every enabled webhook
+func Configured(emailTo string) ([]Notifier, error) {
+	var notifiers []Notifier
+	if emailTo != "" {
+		notifiers = append(notifiers, &EmailNotifier{To: emailTo})
+	}
+
+	webhooks, err := config.LoadWebhooks()
+	if err != nil {
+		return notifiers, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+	for _, webhook := range webhooks {
+		if webhook.Disabled {
+			continue
+		}
+		notifiers = append(notifiers, NewWebhookNotifier(webhook))
+	}
+
+	return notifiers, nil
+}
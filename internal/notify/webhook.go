@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"apiwatcher/internal/config"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of timestamp + "." + body when a secret is set
+	SignatureHeader = "X-ApiWatcher-Signature"
+	// TimestampHeader carries the unix time the request was sent, covered by the
+	// signature so receivers can reject replayed requests
+	TimestampHeader = "X-ApiWatcher-Timestamp"
+
+	defaultWebhookRetries = 3
+	initialRetryBackoff   = 1 * time.Second
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// WebhookNotifier posts alerts as JSON to an HTTP endpoint
+type WebhookNotifier struct {
+	Config *config.WebhookConfig
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier for a webhook config
+func NewWebhookNotifier(webhook *config.WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		Config: webhook,
+		Client: &http.Client{Timeout: webhookRequestTimeout},
+	}
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook:" + n.Config.Name
+}
+
+// Notify posts the alert, retrying with exponential backoff on network errors,
+// 429 and 5xx responses. MaxRetries 0 uses the default, a negative value disables retries.
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body, err := n.renderBody(alert)
+	if err != nil {
+		return err
+	}
+
+	retries := n.Config.MaxRetries
+	if retries == 0 {
+		retries = defaultWebhookRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	backoff := initialRetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		retryable, err := n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+
+	return fmt.Errorf("webhook %s failed: %w", n.Config.Name, lastErr)
+}
+
+// renderBody builds the request body from the template, or from the webhook format
+func (n *WebhookNotifier) renderBody(alert Alert) ([]byte, error) {
+	if n.Config.BodyTemplate == "" {
+		switch n.Config.Format {
+		case config.WebhookFormatSlack:
+			return SlackPayload(alert)
+		case config.WebhookFormatTeams:
+			return TeamsPayload(alert)
+		default:
+			return json.Marshal(alert)
+		}
+	}
+
+	tmpl, err := template.New("body").Funcs(config.WebhookTemplateFuncs).Parse(n.Config.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf("failed to render body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// post sends a single attempt and reports whether a failure is worth retrying
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ApiWatcher")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	for k, v := range n.Config.Headers {
+		req.Header.Set(k, v)
+	}
+	if n.Config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.Config.Secret, timestamp, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of timestamp + "." + body using secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"apiwatcher/internal/config"
+)
+
+// receiver is a webhook endpoint that records the requests it gets
+type receiver struct {
+	mutex   sync.Mutex
+	bodies  [][]byte
+	headers []http.Header
+	status  int // Response status (0 = 200)
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+}
+
+func newTestWebhook(t *testing.T, r *receiver, webhook config.WebhookConfig) *WebhookNotifier {
+	t.Helper()
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	webhook.URL = server.URL
+	n := NewWebhookNotifier(&webhook)
+	n.Client = server.Client()
+	return n
+}
+
+func TestWebhookFormats(t *testing.T) {
+	a := formatCases["check failure"]
+	tests := map[string]func(Alert) ([]byte, error){
+		config.WebhookFormatSlack: SlackPayload,
+		config.WebhookFormatTeams: TeamsPayload,
+	}
+
+	for format, payload := range tests {
+		t.Run(format, func(t *testing.T) {
+			r := &receiver{}
+			n := newTestWebhook(t, r, config.WebhookConfig{Name: format, Format: format, Secret: "s3cret"})
+			if err := n.Notify(context.Background(), a); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			if len(r.bodies) != 1 {
+				t.Fatalf("got %d requests, want 1", len(r.bodies))
+			}
+			want, err := payload(a)
+			if err != nil {
+				t.Fatalf("payload: %v", err)
+			}
+			assertJSON(t, r.bodies[0], string(want))
+
+			header := r.headers[0]
+			if got := header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			timestamp := header.Get(TimestampHeader)
+			if got, want := header.Get(SignatureHeader), "sha256="+Sign("s3cret", timestamp, r.bodies[0]); got != want {
+				t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+			}
+		})
+	}
+}
+
+func TestWebhookRejected(t *testing.T) {
+	r := &receiver{status: http.StatusBadRequest}
+	n := newTestWebhook(t, r, config.WebhookConfig{Name: "slack", Format: config.WebhookFormatSlack})
+	if err := n.Notify(context.Background(), formatCases["resolved"]); err == nil {
+		t.Fatal("Notify succeeded against an endpoint answering 400")
+	}
+	if len(r.bodies) != 1 {
+		t.Errorf("got %d requests, want 1 (4xx isn't retried)", len(r.bodies))
+	}
+}
@@ -0,0 +1,200 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronExpr is a parsed 5-field cron expression (minute hour day-of-month month day-of-week)
+type CronExpr struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	anyDay   bool // day-of-month field was "*"
+	anyWeek  bool // day-of-week field was "*"
+}
+
+// cronField describes the valid range and names for a cron field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField  = cronField{name: "minute", min: 0, max: 59}
+	hourField    = cronField{name: "hour", min: 0, max: 23}
+	dayField     = cronField{name: "day-of-month", min: 1, max: 31}
+	monthField   = cronField{name: "month", min: 1, max: 12, names: map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}}
+	weekdayField = cronField{name: "day-of-week", min: 0, max: 7, names: map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}}
+)
+
+// cronDescriptors maps the common @-shorthands to their 5-field equivalents
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard 5-field cron expression or an @-descriptor
+func ParseCron(spec string) (*CronExpr, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	expr := &CronExpr{
+		anyDay:  fields[2] == "*",
+		anyWeek: fields[4] == "*",
+	}
+
+	var err error
+	if expr.minutes, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if expr.hours, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if expr.days, err = parseCronField(fields[2], dayField); err != nil {
+		return nil, err
+	}
+	if expr.months, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if expr.weekdays, err = parseCronField(fields[4], weekdayField); err != nil {
+		return nil, err
+	}
+
+	// Sunday can be written as 0 or 7
+	if expr.weekdays&(1<<7) != 0 {
+		expr.weekdays |= 1
+	}
+
+	return expr, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps into a bitset
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, field.name)
+			}
+			step = s
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*":
+			low, high = field.min, field.max
+		case strings.Contains(rangePart, "-"):
+			lowStr, highStr, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowStr, field); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(highStr, field); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+			}
+		default:
+			v, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			low, high = v, v
+			// "5/10" means starting at 5 every 10
+			if hasStep {
+				high = field.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue parses a single number or name within a field's range
+func parseCronValue(value string, field cronField) (int, error) {
+	if n, ok := field.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, field.name)
+	}
+	if n < field.min || n > field.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", n, field.min, field.max, field.name)
+	}
+	return n, nil
+}
+
+// Next returns the first matching time strictly after the given time,
+// or the zero time if nothing matches within the next five years
+func (c *CronExpr) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the standard cron rule: when both day fields are restricted,
+// a day matches if either of them does
+func (c *CronExpr) dayMatches(t time.Time) bool {
+	dayMatch := c.days&(1<<uint(t.Day())) != 0
+	weekMatch := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.anyDay && c.anyWeek:
+		return true
+	case c.anyDay:
+		return weekMatch
+	case c.anyWeek:
+		return dayMatch
+	default:
+		return dayMatch || weekMatch
+	}
+}
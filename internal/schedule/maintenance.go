@@ -0,0 +1,196 @@
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Maintenance window recurrences
+const (
+	RecurrenceNone   = ""       // One-off window
+	RecurrenceWeekly = "weekly" // Repeats every week at the same weekday and time
+)
+
+const week = 7 * 24 * time.Hour
+
+// MaintenanceWindow is a period during which checks still run but their results
+// are marked as maintenance: they don't count against uptime and don't alert
+type MaintenanceWindow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"`
+	Targets    []string  `json:"targets,omitempty"` // Website URLs (empty or "*" = every website)
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Recurrence string    `json:"recurrence,omitempty"` // RecurrenceNone or RecurrenceWeekly
+}
+
+// Validate checks the maintenance window fields
+func (w *MaintenanceWindow) Validate() error {
+	if w.Start.IsZero() || w.End.IsZero() {
+		return fmt.Errorf("start and end are required")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	switch w.Recurrence {
+	case RecurrenceNone:
+	case RecurrenceWeekly:
+		if w.End.Sub(w.Start) >= week {
+			return fmt.Errorf("a weekly window must be shorter than a week")
+		}
+	default:
+		return fmt.Errorf("unknown recurrence: %q", w.Recurrence)
+	}
+	return nil
+}
+
+// Covers reports whether the window applies to a website
+func (w *MaintenanceWindow) Covers(website string) bool {
+	if len(w.Targets) == 0 {
+		return true
+	}
+	for _, target := range w.Targets {
+		if target == "*" || target == website {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveAt reports whether the window is in effect at the given time
+func (w *MaintenanceWindow) ActiveAt(t time.Time) bool {
+	if t.Before(w.Start) {
+		return false
+	}
+	if w.Recurrence == RecurrenceWeekly {
+		return t.Sub(w.Start)%week < w.End.Sub(w.Start)
+	}
+	return t.Before(w.End)
+}
+
+// Expired reports whether a one-off window has ended for good
+func (w *MaintenanceWindow) Expired(t time.Time) bool {
+	return w.Recurrence == RecurrenceNone && !t.Before(w.End)
+}
+
+// MaintenanceStore holds maintenance windows persisted to a JSON file
+type MaintenanceStore struct {
+	path    string
+	windows []*MaintenanceWindow
+	mutex   sync.RWMutex
+}
+
+// NewMaintenanceStore loads the maintenance windows stored at path
+func NewMaintenanceStore(path string) (*MaintenanceStore, error) {
+	store := &MaintenanceStore{path: path, windows: []*MaintenanceWindow{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read maintenance windows: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.windows); err != nil {
+		return store, fmt.Errorf("failed to unmarshal maintenance windows: %w", err)
+	}
+	return store, nil
+}
+
+// Save adds a window, or replaces the window with the same ID
+func (s *MaintenanceStore) Save(window MaintenanceWindow) (*MaintenanceWindow, error) {
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+	if window.ID == "" {
+		window.ID = newWindowID()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replaced := false
+	for i, existing := range s.windows {
+		if existing.ID == window.ID {
+			s.windows[i] = &window
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.windows = append(s.windows, &window)
+	}
+	return &window, s.saveLocked()
+}
+
+// Remove deletes a window by ID
+func (s *MaintenanceStore) Remove(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, window := range s.windows {
+		if window.ID == id {
+			s.windows = append(s.windows[:i], s.windows[i+1:]...)
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("maintenance window not found: %s", id)
+}
+
+// List returns all windows that haven't expired
+func (s *MaintenanceStore) List() []MaintenanceWindow {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+	windows := make([]MaintenanceWindow, 0, len(s.windows))
+	for _, window := range s.windows {
+		if !window.Expired(now) {
+			windows = append(windows, *window)
+		}
+	}
+	return windows
+}
+
+// Active returns the window covering a website at the given time, or nil.
+// A nil store never reports maintenance.
+func (s *MaintenanceStore) Active(website string, t time.Time) *MaintenanceWindow {
+	if s == nil {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, window := range s.windows {
+		if window.Covers(website) && window.ActiveAt(t) {
+			found := *window
+			return &found
+		}
+	}
+	return nil
+}
+
+// saveLocked writes the windows to disk. Caller must hold the write lock.
+func (s *MaintenanceStore) saveLocked() error {
+	data, err := json.MarshalIndent(s.windows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance windows: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write maintenance windows: %w", err)
+	}
+	return nil
+}
+
+func newWindowID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
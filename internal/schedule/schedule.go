@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Schedule controls how often a single website is checked.
+// A cron expression takes precedence over the interval; jitter is added to either.
+type Schedule struct {
+	IntervalSeconds int    `json:"interval_seconds,omitempty"` // Seconds between checks (0 = global worker sleep time)
+	JitterSeconds   int    `json:"jitter_seconds,omitempty"`   // Random delay of up to this many seconds added to each run
+	Cron            string `json:"cron,omitempty"`             // Optional 5-field cron expression, e.g. "*/5 9-17 * * 1-5"
+}
+
+// Validate checks the schedule fields
+func (s *Schedule) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.IntervalSeconds < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	if s.JitterSeconds < 0 {
+		return fmt.Errorf("jitter must not be negative")
+	}
+	if s.Cron != "" {
+		if _, err := ParseCron(s.Cron); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Next returns the next run time after the given time.
+// defaultInterval is used when neither a cron expression nor an interval is set.
+func (s *Schedule) Next(after time.Time, defaultInterval time.Duration) time.Time {
+	var next time.Time
+
+	switch {
+	case s != nil && s.Cron != "":
+		expr, err := ParseCron(s.Cron)
+		if err == nil {
+			next = expr.Next(after)
+		}
+	case s != nil && s.IntervalSeconds > 0:
+		next = after.Add(time.Duration(s.IntervalSeconds) * time.Second)
+	}
+
+	// Fall back to the default interval (also covers invalid cron expressions)
+	if next.IsZero() {
+		next = after.Add(defaultInterval)
+	}
+
+	if s != nil && s.JitterSeconds > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.JitterSeconds) * int64(time.Second))))
+	}
+	return next
+}
+
+// Describe returns a short human readable description of the schedule
+func (s *Schedule) Describe(defaultInterval time.Duration) string {
+	var desc string
+	switch {
+	case s != nil && s.Cron != "":
+		desc = fmt.Sprintf("cron %q", s.Cron)
+	case s != nil && s.IntervalSeconds > 0:
+		desc = fmt.Sprintf("every %v", time.Duration(s.IntervalSeconds)*time.Second)
+	default:
+		desc = fmt.Sprintf("every %v (default)", defaultInterval)
+	}
+	if s != nil && s.JitterSeconds > 0 {
+		desc += fmt.Sprintf(" +%ds jitter", s.JitterSeconds)
+	}
+	return desc
+}
@@ -0,0 +1,241 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// KeyEnv overrides the key file with a base64 encoded 32 byte key, e.g. to keep
+// the key off the disk holding the secrets
+const KeyEnv = "APIWATCHER_SECRET_KEY"
+
+const (
+	keyFile     = "secret.key"
+	secretsFile = "secrets.json"
+	keySize     = 32 // AES-256
+)
+
+// namePattern restricts secret names to what a {{secret.name}} placeholder can reference
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Store keeps named secrets encrypted with AES-GCM in a JSON file. Only names are
+// stored in clear; each value is sealed with its name as additional data so
+// ciphertexts can't be swapped between names.
+type Store struct {
+	dir    string
+	aead   cipher.AEAD
+	sealed map[string]string // Name -> base64(nonce | ciphertext)
+	mutex  sync.RWMutex
+}
+
+// DefaultDir returns the directory of the secret store, next to the snapshots
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatal("Cannot find home directory:", err)
+	}
+	return filepath.Join(home, ".url-checker", "secrets")
+}
+
+// DefaultKeyPath returns the key file of the secret store. It lives in the user's
+// config directory, away from the secrets, so a copy of ~/.url-checker (e.g. a
+// backup or a shared snapshot folder) doesn't carry the key with it.
+func DefaultKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		log.Fatal("Cannot find config directory:", err)
+	}
+	return filepath.Join(dir, "apiwatcher", keyFile)
+}
+
+// OpenDefault opens the secret store in DefaultDir with the key at DefaultKeyPath
+func OpenDefault() (*Store, error) {
+	return Open(DefaultDir(), DefaultKeyPath())
+}
+
+// Open opens (creating if needed) a secret store rooted at dir. The key is read
+// from KeyEnv if set, otherwise from (or generated into) keyPath.
+func Open(dir, keyPath string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create secret directory: %w", err)
+	}
+	key, err := loadKey(dir, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	store := &Store{dir: dir, aead: aead, sealed: map[string]string{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, secretsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.sealed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secrets: %w", err)
+	}
+	return store, nil
+}
+
+func loadKey(dir, path string) ([]byte, error) {
+	if encoded := os.Getenv(KeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("%s must be a base64 encoded %d byte key", KeyEnv, keySize)
+		}
+		return key, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create secret key directory: %w", err)
+	}
+	if err := migrateKey(filepath.Join(dir, keyFile), path); err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("invalid secret key file %s", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read secret key: %w", err)
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secret key: %w", err)
+	}
+	return key, nil
+}
+
+// migrateKey moves a key file from where earlier versions generated it, next to
+// the secrets, to path
+func migrateKey(old, path string) error {
+	if old == path {
+		return nil
+	}
+	key, err := os.ReadFile(old)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read secret key: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("secret key found both in %s and %s, remove the one that doesn't decrypt the secrets", old, path)
+	}
+	// Copy rather than rename, the config directory may be on another file system
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return fmt.Errorf("failed to move secret key: %w", err)
+	}
+	if err := os.Remove(old); err != nil {
+		return fmt.Errorf("failed to remove secret key %s after moving it: %w", old, err)
+	}
+	log.Printf("[SECRETS] Moved the secret key from %s to %s\n", old, path)
+	return nil
+}
+
+// ValidateName checks that a secret name can be referenced from a placeholder
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use letters, digits, _ and -)", name)
+	}
+	return nil
+}
+
+// Set encrypts and stores a secret, replacing any secret with the same name
+func (s *Store) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sealed[name] = base64.StdEncoding.EncodeToString(sealed)
+	return s.saveLocked()
+}
+
+// Get decrypts a secret
+func (s *Store) Get(name string) (string, error) {
+	s.mutex.RLock()
+	encoded, exists := s.sealed[name]
+	s.mutex.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("secret not found: %s", name)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupted", name)
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	value, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s (wrong key?): %w", name, err)
+	}
+	return string(value), nil
+}
+
+// Delete removes a secret
+func (s *Store) Delete(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.sealed[name]; !exists {
+		return fmt.Errorf("secret not found: %s", name)
+	}
+	delete(s.sealed, name)
+	return s.saveLocked()
+}
+
+// Names returns the names of the stored secrets, sorted
+func (s *Store) Names() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	names := make([]string, 0, len(s.sealed))
+	for name := range s.sealed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// saveLocked writes the sealed secrets to disk. Caller must hold the write lock.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.sealed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, secretsFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Assertion action types
+const (
+	AssertExists  = "assert_exists"  // Selector matches at least one element
+	AssertVisible = "assert_visible" // Selector matches a visible element
+	AssertText    = "assert_text"    // An element matching Selector (the page if empty) contains Value
+	AssertURL     = "assert_url"     // The page URL matches the Pattern regex
+	AssertCount   = "assert_count"   // Selector matches Value elements ("3", ">=1", "<5", ...)
+	AssertAPI     = "assert_api"     // A response whose URL matches Pattern was received with Status (any < 400 if 0)
+)
+
+const (
+	assertTimeout      = 10 * time.Second // How long an assertion waits for its condition
+	assertPollInterval = 250 * time.Millisecond
+)
+
+// IsAssertion reports whether an action type is an assertion
+func IsAssertion(actionType string) bool {
+	switch actionType {
+	case AssertExists, AssertVisible, AssertText, AssertURL, AssertCount, AssertAPI:
+		return true
+	}
+	return false
+}
+
+// ValidateAssertion checks that an assertion action has the fields its type needs
+func ValidateAssertion(a models.SnapshotAction) error {
+	switch a.Type {
+	case AssertExists, AssertVisible:
+		if a.Selector == "" {
+			return fmt.Errorf("%s requires a selector", a.Type)
+		}
+	case AssertText:
+		if a.Value == "" {
+			return fmt.Errorf("%s requires the expected text as value", a.Type)
+		}
+	case AssertCount:
+		if a.Selector == "" {
+			return fmt.Errorf("%s requires a selector", a.Type)
+		}
+		if _, _, err := parseCount(a.Value); err != nil {
+			return err
+		}
+	case AssertURL, AssertAPI:
+		if a.Pattern == "" {
+			return fmt.Errorf("%s requires a pattern", a.Type)
+		}
+		if _, err := regexp.Compile(a.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", a.Pattern, err)
+		}
+		if a.Status != 0 && (a.Status < 100 || a.Status > 599) {
+			return fmt.Errorf("invalid status: %d", a.Status)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type: %s", a.Type)
+	}
+	return nil
+}
+
+// describeAssertion returns a readable description of an assertion for logs
+func describeAssertion(a models.SnapshotAction) string {
+	switch a.Type {
+	case AssertExists:
+		return fmt.Sprintf("Assert '%s' exists", a.Selector)
+	case AssertVisible:
+		return fmt.Sprintf("Assert '%s' is visible", a.Selector)
+	case AssertText:
+		if a.Selector == "" {
+			return fmt.Sprintf("Assert page contains %q", a.Value)
+		}
+		return fmt.Sprintf("Assert '%s' contains %q", a.Selector, a.Value)
+	case AssertURL:
+		return fmt.Sprintf("Assert URL matches %s", a.Pattern)
+	case AssertCount:
+		return fmt.Sprintf("Assert '%s' count %s", a.Selector, a.Value)
+	case AssertAPI:
+		return fmt.Sprintf("Assert API %s returned %s", a.Pattern, statusDescription(a.Status))
+	}
+	return a.Type
+}
+
+// ==========================
+// Evaluation
+// ==========================
+
+// elementState is what the page reports about the elements matching a selector
+type elementState struct {
+	Count     int  `json:"count"`
+	Visible   bool `json:"visible"`    // At least one match is visible
+	TextFound bool `json:"text_found"` // At least one match contains the expected text
+}
+
+// inspectScript evaluates the elements matching a selector (the whole page if empty)
+const inspectScript = `(function(selector, text) {
+	var elements = selector ? Array.from(document.querySelectorAll(selector)) : [document.body];
+	var state = {count: elements.length, visible: false, text_found: false};
+	elements.forEach(function(el) {
+		if (!el) return;
+		var rect = el.getBoundingClientRect();
+		var style = window.getComputedStyle(el);
+		if (rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none') {
+			state.visible = true;
+		}
+		if (text && (el.innerText || el.textContent || '').indexOf(text) !== -1) {
+			state.text_found = true;
+		}
+	});
+	return state;
+})(%s, %s)`
+
+// runAssertion evaluates an assertion, polling until it holds or assertTimeout expires
+func runAssertion(ctx context.Context, a models.SnapshotAction, responses *responseLog) error {
+	if err := ValidateAssertion(a); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(assertTimeout)
+	for {
+		err := checkAssertion(ctx, a, responses)
+		if err == nil || time.Now().After(deadline) || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(assertPollInterval):
+		}
+	}
+}
+
+// checkAssertion evaluates an assertion once
+func checkAssertion(ctx context.Context, a models.SnapshotAction, responses *responseLog) error {
+	switch a.Type {
+	case AssertURL:
+		var location string
+		if err := chromedp.Run(ctx, chromedp.Location(&location)); err != nil {
+			return fmt.Errorf("failed to read page URL: %w", err)
+		}
+		if !regexp.MustCompile(a.Pattern).MatchString(location) {
+			return fmt.Errorf("page URL %s doesn't match %s", location, a.Pattern)
+		}
+		return nil
+
+	case AssertAPI:
+		if seen := responses.match(regexp.MustCompile(a.Pattern), a.Status); seen != nil {
+			return fmt.Errorf("no response matching %s with %s (seen: %s)", a.Pattern, statusDescription(a.Status), strings.Join(seen, ", "))
+		}
+		return nil
+	}
+
+	// Element assertions recorded with locators check the element they find, so a
+	// redesign that breaks the CSS path doesn't fail the assertion. Counts are
+	// about every element the selector matches and keep using it.
+	target := a.Selector
+	if len(a.Locators) > 0 && a.Type != AssertCount {
+		if marked, index, err := resolveLocators(ctx, a.Locators, "assert"); err == nil && index >= 0 {
+			target = marked
+		}
+	}
+
+	selector, _ := json.Marshal(target)
+	text, _ := json.Marshal(a.Value)
+	var state elementState
+	if err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(inspectScript, selector, text), &state)); err != nil {
+		return fmt.Errorf("failed to inspect %q: %w", a.Selector, err)
+	}
+
+	switch a.Type {
+	case AssertExists:
+		if state.Count == 0 {
+			return fmt.Errorf("no element matches %s", a.Selector)
+		}
+	case AssertVisible:
+		if !state.Visible {
+			return fmt.Errorf("no visible element matches %s (%d hidden)", a.Selector, state.Count)
+		}
+	case AssertText:
+		if !state.TextFound {
+			target := a.Selector
+			if target == "" {
+				target = "the page"
+			}
+			return fmt.Errorf("%s doesn't contain %q", target, a.Value)
+		}
+	case AssertCount:
+		op, expected, _ := parseCount(a.Value)
+		if !compareCount(state.Count, op, expected) {
+			return fmt.Errorf("%d element(s) match %s, expected %s%d", state.Count, a.Selector, op, expected)
+		}
+	}
+	return nil
+}
+
+// parseCount parses an expected element count with an optional comparison operator
+func parseCount(value string) (string, int, error) {
+	value = strings.TrimSpace(value)
+	op := "=="
+	for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			op = candidate
+			value = strings.TrimSpace(strings.TrimPrefix(value, candidate))
+			break
+		}
+	}
+	if op == "=" {
+		op = "=="
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("invalid element count %q", value)
+	}
+	return op, n, nil
+}
+
+func compareCount(count int, op string, expected int) bool {
+	switch op {
+	case ">=":
+		return count >= expected
+	case "<=":
+		return count <= expected
+	case ">":
+		return count > expected
+	case "<":
+		return count < expected
+	default:
+		return count == expected
+	}
+}
+
+func statusDescription(status int) string {
+	if status == 0 {
+		return "a successful status"
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+// ==========================
+// Observed Responses
+// ==========================
+
+// maxListedResponses caps the responses quoted when an API assertion fails
+const maxListedResponses = 5
+
+// responseLog records the API responses received during a replay
+type responseLog struct {
+	mutex     sync.Mutex
+	responses []observedResponse
+}
+
+type observedResponse struct {
+	URL    string
+	Status int
+}
+
+func (l *responseLog) add(url string, status int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.responses = append(l.responses, observedResponse{URL: url, Status: status})
+}
+
+// match returns nil if a response matching pattern was received with the expected
+// status, otherwise the (latest) responses whose URL matched
+func (l *responseLog) match(pattern *regexp.Regexp, status int) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	seen := []string{}
+	for _, r := range l.responses {
+		if !pattern.MatchString(r.URL) {
+			continue
+		}
+		if (status == 0 && r.Status < 400) || r.Status == status {
+			return nil
+		}
+		seen = append(seen, fmt.Sprintf("%d %s", r.Status, r.URL))
+	}
+	if len(seen) == 0 {
+		return []string{"none"}
+	}
+	if len(seen) > maxListedResponses {
+		seen = seen[len(seen)-maxListedResponses:]
+	}
+	return seen
+}
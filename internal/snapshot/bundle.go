@@ -0,0 +1,362 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"apiwatcher/internal/secrets"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BundleSchemaVersion is the version of the bundle format written by ExportBundle.
+// Bundles with a newer schema are rejected.
+const BundleSchemaVersion = 1
+
+const (
+	manifestFile  = "manifest.json"
+	maxBundleFile = 10 << 20 // Largest file read from a bundle
+)
+
+// idPattern keeps imported IDs from pointing outside the snapshot directory
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Import formats
+const (
+	FormatBundle         = "bundle"          // Zip written by ExportBundle
+	FormatChromeRecorder = "chrome_recorder" // Chrome DevTools Recorder JSON export
+	FormatScript         = "script"          // Playwright or Puppeteer script
+)
+
+// Manifest describes the snapshots of a bundle. A bundle is a zip holding
+// manifest.json and one snapshots/<id>.json per snapshot, in the format
+// snapshots are saved in.
+type Manifest struct {
+	SchemaVersion int           `json:"schema_version"`
+	Generator     string        `json:"generator"`
+	ExportedAt    time.Time     `json:"exported_at"`
+	Redaction     string        `json:"redaction"` // How passwords were found and left out
+	Snapshots     []BundleEntry `json:"snapshots"`
+}
+
+// redactionNote is the manifest's Redaction: password detection is heuristic for
+// snapshots recorded before password fields were flagged
+const redactionNote = "Values typed into fields flagged sensitive, or whose selector, locators or label look like a password field, " +
+	"were replaced with \"[redacted]\". Fields not recognized as passwords are exported as recorded."
+
+// BundleEntry is a snapshot of a bundle with what it needs to be replayed elsewhere
+type BundleEntry struct {
+	File        string            `json:"file"` // Path of the snapshot in the zip
+	ID          string            `json:"id"`
+	Name        string            `json:"name,omitempty"`
+	URL         string            `json:"url"`
+	Version     int               `json:"version,omitempty"` // Version that was exported
+	Actions     int               `json:"actions"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	Assertions  []string          `json:"assertions,omitempty"`  // What the snapshot verifies
+	Secrets     []string          `json:"secrets,omitempty"`     // {{secret.name}} references; values aren't exported
+	Environment []string          `json:"environment,omitempty"` // {{env.NAME}} references
+	Redacted    []int             `json:"redacted,omitempty"`    // Indexes of the actions whose password typed in clear wasn't exported
+}
+
+// ImportResult lists the imported snapshots and what has to be fixed before they replay
+type ImportResult struct {
+	IDs      []string `json:"ids"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ==========================
+// Export
+// ==========================
+
+// ExportBundle writes snapshots to a bundle. Passwords typed in clear are left
+// out as far as they can be recognized (see isPasswordInput), and secrets are
+// only exported as references.
+func ExportBundle(snapshots []*Snapshot) ([]byte, error) {
+	manifest := Manifest{
+		SchemaVersion: BundleSchemaVersion,
+		Generator:     "apiwatcher",
+		ExportedAt:    time.Now().UTC(),
+		Redaction:     redactionNote,
+		Snapshots:     []BundleEntry{},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, s := range snapshots {
+		exported := redactPasswords(s)
+		entry := bundleEntry(exported)
+		data, err := json.MarshalIndent(exported, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot %s: %w", s.ID, err)
+		}
+		if err := writeZipFile(zw, entry.File, data); err != nil {
+			return nil, err
+		}
+		manifest.Snapshots = append(manifest.Snapshots, entry)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeZipFile(zw, manifestFile, data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// bundleEntry describes a snapshot whose passwords were already redacted
+func bundleEntry(s *Snapshot) BundleEntry {
+	entry := BundleEntry{
+		File:      path.Join("snapshots", s.ID+".json"),
+		ID:        s.ID,
+		Name:      s.Name,
+		URL:       s.URL,
+		Version:   s.Version,
+		Actions:   len(s.Actions),
+		Variables: s.Variables,
+	}
+	refs := map[string]bool{}
+	collect := func(value string) {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+			refs[match[1]] = true
+		}
+	}
+	collect(s.URL)
+	for _, value := range s.Variables {
+		collect(value)
+	}
+	for i, a := range s.Actions {
+		collect(a.URL)
+		collect(a.Selector)
+		collect(a.Value)
+		collect(a.Pattern)
+		for _, locator := range a.Locators {
+			collect(locator.Value)
+			collect(locator.Name)
+		}
+		if IsAssertion(a.Type) {
+			entry.Assertions = append(entry.Assertions, describeAssertion(a))
+		}
+		if a.Sensitive && a.Value == redactedValue {
+			entry.Redacted = append(entry.Redacted, i)
+		}
+	}
+	for ref := range refs {
+		if name, ok := strings.CutPrefix(ref, secretPrefix); ok {
+			entry.Secrets = append(entry.Secrets, name)
+		} else if name, ok := strings.CutPrefix(ref, envPrefix); ok {
+			entry.Environment = append(entry.Environment, name)
+		}
+	}
+	sort.Strings(entry.Secrets)
+	sort.Strings(entry.Environment)
+	return entry
+}
+
+// ==========================
+// Import
+// ==========================
+
+// ReadBundle reads the snapshots of a bundle without saving them
+func ReadBundle(data []byte) (*Manifest, []*Snapshot, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	raw, err := readZipFile(files, manifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > BundleSchemaVersion {
+		return nil, nil, fmt.Errorf("unsupported bundle schema version %d (supported: 1 to %d)", manifest.SchemaVersion, BundleSchemaVersion)
+	}
+
+	snapshots := make([]*Snapshot, 0, len(manifest.Snapshots))
+	for _, entry := range manifest.Snapshots {
+		raw, err := readZipFile(files, entry.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		var s Snapshot
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, nil, fmt.Errorf("invalid snapshot %s: %w", entry.File, err)
+		}
+		if !idPattern.MatchString(s.ID) || s.URL == "" {
+			return nil, nil, fmt.Errorf("snapshot %s has no valid ID or URL", entry.File)
+		}
+		snapshots = append(snapshots, &s)
+	}
+	return &manifest, snapshots, nil
+}
+
+func readZipFile(files map[string]*zip.File, name string) ([]byte, error) {
+	f, exists := files[name]
+	if !exists {
+		return nil, fmt.Errorf("bundle has no %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxBundleFile+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxBundleFile {
+		return nil, fmt.Errorf("%s is larger than %d bytes", name, maxBundleFile)
+	}
+	return data, nil
+}
+
+// Import saves the snapshots found in data, in one of the import formats.
+// Snapshots of a bundle keep their ID unless a snapshot with that ID already
+// exists: overwrite saves the import as its next version, otherwise the import
+// gets a new ID. Converted recordings and scripts always get a new ID. Actions
+// that fail validation are skipped and reported as warnings.
+func Import(format string, data []byte, overwrite bool) (*ImportResult, error) {
+	result := &ImportResult{IDs: []string{}}
+	var snapshots []*Snapshot
+	var change string
+
+	switch format {
+	case FormatBundle:
+		_, bundled, err := ReadBundle(data)
+		if err != nil {
+			return nil, err
+		}
+		snapshots, change = bundled, "Imported from a bundle"
+		for _, s := range snapshots {
+			result.Warnings = append(result.Warnings, dropInvalidActions(s)...)
+		}
+	case FormatChromeRecorder:
+		s, warnings, err := ParseChromeRecording(data)
+		if err != nil {
+			return nil, err
+		}
+		snapshots, change = []*Snapshot{s}, "Imported from a Chrome Recorder recording"
+		result.Warnings = append(result.Warnings, warnings...)
+	case FormatScript:
+		s, warnings, err := ConvertScript(string(data))
+		if err != nil {
+			return nil, err
+		}
+		snapshots, change = []*Snapshot{s}, "Converted from a script"
+		result.Warnings = append(result.Warnings, warnings...)
+	default:
+		return nil, fmt.Errorf("unknown import format: %q", format)
+	}
+
+	editMutex.Lock()
+	defer editMutex.Unlock()
+
+	for _, s := range snapshots {
+		if current, err := LoadByID(s.ID); err == nil {
+			if overwrite {
+				// Passwords aren't exported; keep typing the ones this machine has
+				_ = restorePasswords(s, current)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: saved as version %d of the existing snapshot", s.ID, current.Version+1))
+			} else {
+				id := newSnapshotID()
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: a snapshot with this ID exists, imported as %s", s.ID, id))
+				s.ID = id
+			}
+		}
+		if err := SaveVersion(s, change, ""); err != nil {
+			return nil, fmt.Errorf("failed to save snapshot %s: %w", s.ID, err)
+		}
+		result.IDs = append(result.IDs, s.ID)
+		result.Warnings = append(result.Warnings, importWarnings(s)...)
+		log.Printf("[SNAPSHOT] Imported snapshot %s (version %d, %d actions)\n", s.ID, s.Version, len(s.Actions))
+	}
+	return result, nil
+}
+
+// dropInvalidActions removes the actions of a bundled snapshot that can't be
+// replayed, the way the converters skip the steps they can't convert
+func dropInvalidActions(s *Snapshot) []string {
+	var warnings []string
+	valid := make([]models.SnapshotAction, 0, len(s.Actions))
+	for i, a := range s.Actions {
+		if err := ValidateAction(a); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: action %d: %v, skipped", s.ID, i+1, err))
+			continue
+		}
+		valid = append(valid, a)
+	}
+	s.Actions = valid
+	return warnings
+}
+
+// importWarnings lists what an imported snapshot is missing to replay here
+func importWarnings(s *Snapshot) []string {
+	var warnings []string
+	entry := bundleEntry(s)
+	for _, i := range entry.Redacted {
+		warnings = append(warnings, fmt.Sprintf("%s: action %d types a password that wasn't exported, reference a secret instead", s.ID, i+1))
+	}
+	if len(entry.Secrets) > 0 {
+		store, err := secrets.OpenDefault()
+		for _, name := range entry.Secrets {
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: secret %s can't be checked: %v", s.ID, name, err))
+			} else if _, err := store.Get(name); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: secret %s has to be set on this machine", s.ID, name))
+			}
+		}
+	}
+	for _, name := range entry.Environment {
+		if _, exists := os.LookupEnv(name); !exists {
+			warnings = append(warnings, fmt.Sprintf("%s: environment variable %s is not set", s.ID, name))
+		}
+	}
+	return warnings
+}
+
+// newSnapshotID returns an ID for a new snapshot, as the recorder assigns them
+func newSnapshotID() string {
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// newImportedSnapshot returns an empty snapshot for a converted recording or script
+func newImportedSnapshot(name string) *Snapshot {
+	return &Snapshot{
+		ID:        newSnapshotID(),
+		Name:      name,
+		Actions:   []models.SnapshotAction{},
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,188 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ==========================
+// Chrome DevTools Recorder Import
+// ==========================
+
+// chromeRecording is a recording exported as JSON from the Recorder panel of Chrome DevTools
+type chromeRecording struct {
+	Title string       `json:"title"`
+	Steps []chromeStep `json:"steps"`
+}
+
+type chromeStep struct {
+	Type      string            `json:"type"`
+	Target    string            `json:"target,omitempty"` // "main" unless the step runs in a popup
+	Frame     []int             `json:"frame,omitempty"`  // Path of the iframe the step runs in
+	URL       string            `json:"url,omitempty"`
+	Value     string            `json:"value,omitempty"`
+	Key       string            `json:"key,omitempty"`
+	Selectors []json.RawMessage `json:"selectors,omitempty"` // Each a selector or a chain of them
+	Count     *int              `json:"count,omitempty"`
+	Operator  string            `json:"operator,omitempty"`
+	Visible   *bool             `json:"visible,omitempty"`
+}
+
+// recordedKeys are the keys the recorder captures, the ones keydown actions replay
+var recordedKeys = map[string]bool{"Enter": true, "ArrowDown": true, "ArrowUp": true}
+
+// ariaSelectorPattern matches Recorder ARIA selectors, e.g. aria/Save[role="button"]
+var ariaSelectorPattern = regexp.MustCompile(`^(.*?)(?:\[role="([^"]+)"\])?$`)
+
+// ParseChromeRecording converts a Chrome DevTools Recorder JSON export to a
+// snapshot. The first navigation becomes the snapshot URL and waitForElement
+// steps become assertions. Steps that can't be replayed are left out and
+// reported as warnings.
+func ParseChromeRecording(data []byte) (*Snapshot, []string, error) {
+	var recording chromeRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, nil, fmt.Errorf("invalid Chrome Recorder JSON: %w", err)
+	}
+	if len(recording.Steps) == 0 {
+		return nil, nil, fmt.Errorf("recording has no steps")
+	}
+
+	s := newImportedSnapshot(strings.TrimSpace(recording.Title))
+	var warnings []string
+	warn := func(i int, format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf("step %d: %s", i+1, fmt.Sprintf(format, args...)))
+	}
+	var lastLocators []models.Locator // Element the keys of keyDown steps go to
+
+	for i, step := range recording.Steps {
+		if (step.Target != "" && step.Target != "main") || len(step.Frame) > 0 {
+			warn(i, "%s in a popup or iframe isn't supported, skipped", step.Type)
+			continue
+		}
+
+		var a models.SnapshotAction
+		switch step.Type {
+		case "navigate":
+			if s.URL == "" {
+				s.URL = step.URL
+				continue
+			}
+			a = models.SnapshotAction{Type: "navigate", URL: step.URL}
+		case "click", "doubleClick", "change":
+			locators := chromeLocators(step.Selectors)
+			if len(locators) == 0 {
+				warn(i, "%s has no selector that can be replayed, skipped", step.Type)
+				continue
+			}
+			lastLocators = locators
+			a = models.SnapshotAction{Type: "click", Selector: firstCSS(locators), Locators: locators}
+			if step.Type == "change" {
+				a.Type = "input"
+				a.Value = step.Value
+			} else if step.Type == "doubleClick" {
+				warn(i, "double click replayed as a single click")
+			}
+		case "keyDown":
+			if !recordedKeys[step.Key] {
+				warn(i, "key %s isn't replayed, skipped", step.Key)
+				continue
+			}
+			if lastLocators == nil {
+				lastLocators = []models.Locator{{Kind: LocatorCSS, Value: "body"}}
+			}
+			a = models.SnapshotAction{Type: "keydown", Key: step.Key, Selector: firstCSS(lastLocators), Locators: lastLocators}
+		case "waitForElement":
+			locators := chromeLocators(step.Selectors)
+			selector := firstCSS(locators)
+			if selector == "" {
+				warn(i, "waitForElement needs a CSS selector to become an assertion, skipped")
+				continue
+			}
+			switch {
+			case step.Count != nil:
+				operator := step.Operator
+				if operator == "" {
+					operator = "=="
+				}
+				a = models.SnapshotAction{Type: AssertCount, Selector: selector, Value: fmt.Sprintf("%s%d", operator, *step.Count)}
+			case step.Visible != nil && !*step.Visible:
+				a = models.SnapshotAction{Type: AssertExists, Selector: selector}
+			default:
+				a = models.SnapshotAction{Type: AssertVisible, Selector: selector}
+			}
+		case "setViewport", "keyUp", "scroll", "hover", "close":
+			// Implied by the steps around them, or irrelevant to a replay
+			continue
+		default:
+			warn(i, "%s steps aren't supported, skipped", step.Type)
+			continue
+		}
+
+		a.Sensitive = isPasswordInput(a)
+		if err := ValidateAction(a); err != nil {
+			warn(i, "%v, skipped", err)
+			continue
+		}
+		s.Actions = append(s.Actions, a)
+	}
+
+	if s.URL == "" {
+		return nil, warnings, fmt.Errorf("recording has no navigate step")
+	}
+	if s.Name == "" {
+		s.Name = s.URL
+	}
+	return s, warnings, nil
+}
+
+// chromeLocators converts Recorder selectors to locators, skipping the ones
+// that reach into shadow roots or iframes
+func chromeLocators(selectors []json.RawMessage) []models.Locator {
+	var locators []models.Locator
+	for _, raw := range selectors {
+		var chain []string
+		if err := json.Unmarshal(raw, &chain); err != nil {
+			var single string
+			if err := json.Unmarshal(raw, &single); err != nil {
+				continue
+			}
+			chain = []string{single}
+		}
+		if len(chain) != 1 {
+			continue
+		}
+		selector := chain[0]
+
+		switch {
+		case strings.HasPrefix(selector, "aria/"):
+			match := ariaSelectorPattern.FindStringSubmatch(strings.TrimPrefix(selector, "aria/"))
+			if match[2] != "" {
+				locators = append(locators, models.Locator{Kind: LocatorRole, Value: match[2], Name: match[1]})
+			} else if match[1] != "" {
+				locators = append(locators, models.Locator{Kind: LocatorText, Value: match[1]})
+			}
+		case strings.HasPrefix(selector, "xpath/"):
+			locators = append(locators, models.Locator{Kind: LocatorXPath, Value: strings.TrimPrefix(selector, "xpath/")})
+		case strings.HasPrefix(selector, "text/"):
+			locators = append(locators, models.Locator{Kind: LocatorText, Value: strings.TrimPrefix(selector, "text/")})
+		case strings.HasPrefix(selector, "pierce/"):
+			// Shadow DOM piercing has no locator equivalent
+		default:
+			locators = append(locators, models.Locator{Kind: LocatorCSS, Value: selector})
+		}
+	}
+	return locators
+}
+
+// firstCSS returns the first CSS locator, used as the action's selector
+func firstCSS(locators []models.Locator) string {
+	for _, l := range locators {
+		if l.Kind == LocatorCSS {
+			return l.Value
+		}
+	}
+	return ""
+}
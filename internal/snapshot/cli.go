@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"apiwatcher/internal/config"
+	"apiwatcher/internal/secrets"
 	"bufio"
 	"fmt"
 	"os"
@@ -24,7 +25,7 @@ func PromptSnapshotFlow(cfg *config.Config) map[string]*Snapshot {
 
 	// List sites
 	for i, s := range cfg.Websites {
-		fmt.Printf("%d) %s\n", i+1, s)
+		fmt.Printf("%d) %s\n", i+1, s.URL)
 	}
 	fmt.Println("Select sites to record (comma separated indices, e.g. 1 or 1,3):")
 	fmt.Print("> ")
@@ -41,7 +42,7 @@ func PromptSnapshotFlow(cfg *config.Config) map[string]*Snapshot {
 			fmt.Println("Skipping invalid selection:", p)
 			continue
 		}
-		url := cfg.Websites[idx-1]
+		url := cfg.Websites[idx-1].URL
 		// Check for existing snapshots
 		existingSnapshots, err := LoadForURL(url)
 		if err != nil {
@@ -116,3 +117,45 @@ func PromptSnapshotFlow(cfg *config.Config) map[string]*Snapshot {
 	}
 	return snapshotsByURL
 }
+
+// offerPasswordSecrets asks whether each recorded password input should be moved
+// to the secret store, so the snapshot only keeps a {{secret.name}} reference
+func offerPasswordSecrets(s *Snapshot, reader *bufio.Reader) {
+	inputs := s.PasswordInputs()
+	if len(inputs) == 0 {
+		return
+	}
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		fmt.Printf("Warning: password fields were recorded in clear, couldn't open the secret store: %v\n", err)
+		return
+	}
+
+	// Replacing collapses earlier actions, so work from the last input backwards
+	for i := len(inputs) - 1; i >= 0; i-- {
+		input := inputs[i]
+		fmt.Printf("\nA password was typed into %s. Store it as a secret instead of saving it in the snapshot? (y/n)\n", input.Selector)
+		fmt.Print("> ")
+		answer, _ := reader.ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) != "y" {
+			continue
+		}
+		fmt.Printf("Secret name (default %s):\n", input.SuggestedName)
+		fmt.Print("> ")
+		name, _ := reader.ReadString('\n')
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = input.SuggestedName
+		}
+		// Store first so the snapshot never references a secret that wasn't saved
+		if err := store.Set(name, s.Actions[input.ActionIndex].Value); err != nil {
+			fmt.Println("Failed to store secret:", err)
+			continue
+		}
+		if _, err := s.ReplaceWithSecret(input.ActionIndex, name); err != nil {
+			fmt.Println("Failed to replace password:", err)
+			continue
+		}
+		fmt.Printf("Stored as %s\n", SecretRef(name))
+	}
+}
@@ -0,0 +1,161 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ActionWait is a step that only waits, for the strategy in its Wait field
+const ActionWait = "wait"
+
+// maxNameLength bounds snapshot names
+const maxNameLength = 100
+
+// editMutex serializes load-edit-save cycles so concurrent edits aren't lost
+var editMutex sync.Mutex
+
+// ValidateAction checks that an action has the fields its type needs to be replayed
+func ValidateAction(a models.SnapshotAction) error {
+	switch a.Type {
+	case "navigate":
+		if a.URL == "" {
+			return fmt.Errorf("navigate requires a url")
+		}
+	case "click", "mousedown", "input", "keydown":
+		if len(actionLocators(a)) == 0 {
+			return fmt.Errorf("%s requires a selector or locators", a.Type)
+		}
+		if a.Type == "keydown" && a.Key == "" {
+			return fmt.Errorf("keydown requires a key")
+		}
+	case "change":
+		if a.Selector == "" {
+			return fmt.Errorf("change requires a selector")
+		}
+	case ActionWait:
+		if a.Wait == nil {
+			return fmt.Errorf("wait requires a wait strategy")
+		}
+	case "":
+		return fmt.Errorf("action type is required")
+	default:
+		if !IsAssertion(a.Type) {
+			return fmt.Errorf("unknown action type: %s", a.Type)
+		}
+		if err := ValidateAssertion(a); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range a.Locators {
+		switch l.Kind {
+		case LocatorTestID, LocatorRole, LocatorText, LocatorCSS, LocatorXPath:
+		default:
+			return fmt.Errorf("unknown locator kind: %q", l.Kind)
+		}
+		if l.Value == "" {
+			return fmt.Errorf("%s locator requires a value", l.Kind)
+		}
+	}
+	if a.Wait != nil {
+		if err := a.Wait.Validate(); err != nil {
+			return fmt.Errorf("invalid wait: %w", err)
+		}
+	}
+	return nil
+}
+
+// ==========================
+// Editing
+// ==========================
+
+// Rename sets the snapshot's display name
+func (s *Snapshot) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("snapshot name is required")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("snapshot name is longer than %d characters", maxNameLength)
+	}
+	s.Name = name
+	return nil
+}
+
+// UpdateAction replaces the action at index
+func (s *Snapshot) UpdateAction(index int, a models.SnapshotAction) error {
+	if err := s.checkIndex(index); err != nil {
+		return err
+	}
+	if err := ValidateAction(a); err != nil {
+		return err
+	}
+	if a.Timestamp == 0 {
+		a.Timestamp = s.Actions[index].Timestamp
+	}
+	s.Actions[index] = a
+	return nil
+}
+
+// InsertAction inserts an action before index (len(Actions) appends it)
+func (s *Snapshot) InsertAction(index int, a models.SnapshotAction) error {
+	if index < 0 || index > len(s.Actions) {
+		return fmt.Errorf("invalid action index %d (snapshot has %d actions)", index, len(s.Actions))
+	}
+	if err := ValidateAction(a); err != nil {
+		return err
+	}
+	s.Actions = append(s.Actions[:index], append([]models.SnapshotAction{a}, s.Actions[index:]...)...)
+	return nil
+}
+
+// DeleteAction removes the action at index
+func (s *Snapshot) DeleteAction(index int) error {
+	if err := s.checkIndex(index); err != nil {
+		return err
+	}
+	s.Actions = append(s.Actions[:index], s.Actions[index+1:]...)
+	return nil
+}
+
+// MoveAction moves the action at from so it ends up at index to
+func (s *Snapshot) MoveAction(from, to int) error {
+	if err := s.checkIndex(from); err != nil {
+		return err
+	}
+	if err := s.checkIndex(to); err != nil {
+		return err
+	}
+	a := s.Actions[from]
+	s.Actions = append(s.Actions[:from], s.Actions[from+1:]...)
+	s.Actions = append(s.Actions[:to], append([]models.SnapshotAction{a}, s.Actions[to:]...)...)
+	return nil
+}
+
+func (s *Snapshot) checkIndex(index int) error {
+	if index < 0 || index >= len(s.Actions) {
+		return fmt.Errorf("invalid action index %d (snapshot has %d actions)", index, len(s.Actions))
+	}
+	return nil
+}
+
+// Edit loads a snapshot, applies edit and saves it as a new version unless edit
+// fails. change describes the edit and note is the author's reason, if any.
+func Edit(id, change, note string, edit func(s *Snapshot) error) (*Snapshot, error) {
+	editMutex.Lock()
+	defer editMutex.Unlock()
+
+	s, err := LoadByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if err := edit(s); err != nil {
+		return nil, err
+	}
+	if err := SaveVersion(s, change, note); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return s, nil
+}
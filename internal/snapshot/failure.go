@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/artifact"
+	"apiwatcher/internal/models"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	maxFailureCaptures = 5                // Failures captured per replay; later ones are only logged
+	captureTimeout     = 15 * time.Second // Time allowed to capture a failed step
+	screenshotQuality  = 80               // JPEG quality of failure screenshots
+)
+
+// FailureCapture is the page state captured when a replay action failed
+type FailureCapture struct {
+	ID          string    // Artifact ID, referenced from alerts
+	ActionIndex int       // Index of the failed action (-1 = initial navigation)
+	ActionType  string    // Action type (click, input, ...)
+	Selector    string    // Action selector
+	Error       string    // Why the action failed
+	PageURL     string    // Page URL when the action failed
+	CapturedAt  time.Time // When the failure was captured
+	Screenshot  []byte    // Full-page JPEG screenshot (nil if it couldn't be taken)
+	DOM         string    // Serialized DOM (empty if it couldn't be read)
+}
+
+// Describe returns a one line description of the failed step
+func (f *FailureCapture) Describe() string {
+	step := "Initial navigation"
+	if f.ActionIndex >= 0 {
+		step = fmt.Sprintf("Step %d (%s", f.ActionIndex+1, f.ActionType)
+		if f.Selector != "" {
+			step += " " + f.Selector
+		}
+		step += ")"
+	}
+	return fmt.Sprintf("%s: %s", step, f.Error)
+}
+
+// captureFailure takes a full-page screenshot and serializes the DOM after an action failed.
+// ctx must be the chromedp tab context, not the (possibly expired) replay timeout context.
+// Captures beyond maxFailureCaptures are skipped (nil is returned).
+func (r *ReplayResult) captureFailure(ctx context.Context, index int, a models.SnapshotAction, actionErr error) *FailureCapture {
+	if len(r.Failures) >= maxFailureCaptures {
+		return nil
+	}
+
+	failure := &FailureCapture{
+		ID:          artifact.NewID(),
+		ActionIndex: index,
+		ActionType:  a.Type,
+		Selector:    a.Selector,
+		Error:       actionErr.Error(),
+		CapturedAt:  time.Now(),
+	}
+
+	captureCtx, cancel := context.WithTimeout(ctx, captureTimeout)
+	defer cancel()
+
+	if err := chromedp.Run(captureCtx, chromedp.Location(&failure.PageURL)); err != nil {
+		failure.PageURL = a.URL
+	}
+	if err := chromedp.Run(captureCtx, chromedp.FullScreenshot(&failure.Screenshot, screenshotQuality)); err != nil {
+		log.Printf("[SNAPSHOT] ⚠️  Failed to capture screenshot: %v\n", err)
+	}
+	if err := chromedp.Run(captureCtx, chromedp.Evaluate(`document.documentElement ? document.documentElement.outerHTML : ""`, &failure.DOM)); err != nil {
+		log.Printf("[SNAPSHOT] ⚠️  Failed to capture DOM: %v\n", err)
+	}
+
+	log.Printf("[SNAPSHOT] 📸 Captured failure artifact %s (screenshot %d bytes, DOM %d bytes)\n",
+		failure.ID, len(failure.Screenshot), len(failure.DOM))
+	r.Failures = append(r.Failures, failure)
+	return failure
+}
@@ -0,0 +1,301 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Locator kinds, from the most to the least robust
+const (
+	LocatorTestID = "testid" // Test attribute (Name, default data-testid) equals Value
+	LocatorRole   = "role"   // ARIA role Value with accessible name Name
+	LocatorText   = "text"   // Text content equals Value
+	LocatorCSS    = "css"    // CSS selector
+	LocatorXPath  = "xpath"  // XPath expression
+)
+
+const (
+	locateTimeout  = 15 * time.Second // How long an action waits for its element
+	locatePoll     = 250 * time.Millisecond
+	maxTextLocator = 60 // Longest text used as a text locator (MAX_TEXT in locatorFunctions)
+
+	// targetAttribute marks the located element so chromedp actions can address it by CSS
+	targetAttribute = "data-apiwatcher-target"
+)
+
+// actionLocators returns the ranked locators of an action. Actions recorded before
+// locators existed fall back to their selector, ARIA label and text.
+func actionLocators(a models.SnapshotAction) []models.Locator {
+	if len(a.Locators) > 0 {
+		return a.Locators
+	}
+
+	var locators []models.Locator
+	if a.Selector != "" {
+		locators = append(locators, models.Locator{Kind: LocatorCSS, Value: a.Selector})
+	}
+	if a.AriaLabel != "" {
+		locators = append(locators, models.Locator{Kind: LocatorCSS, Value: fmt.Sprintf("[aria-label=%q]", a.AriaLabel)})
+	}
+	if text := strings.Join(strings.Fields(a.Text), " "); text != "" && len(text) <= maxTextLocator {
+		locators = append(locators, models.Locator{Kind: LocatorText, Value: text})
+	}
+	return locators
+}
+
+// describeLocator returns a readable form of a locator, e.g. role=button "Save"
+func describeLocator(l models.Locator) string {
+	switch l.Kind {
+	case LocatorTestID:
+		name := l.Name
+		if name == "" {
+			name = "data-testid"
+		}
+		return fmt.Sprintf("%s=%q", name, l.Value)
+	case LocatorRole:
+		return fmt.Sprintf("role=%s %q", l.Value, l.Name)
+	case LocatorText:
+		return fmt.Sprintf("text=%q", l.Value)
+	}
+	return fmt.Sprintf("%s=%s", l.Kind, l.Value)
+}
+
+// locateResult is what resolveLocators reports
+type locateResult struct {
+	Index int `json:"index"` // Matched locator (-1 = none)
+	Count int `json:"count"` // Visible elements it matched
+}
+
+// locate waits until one of the action's locators matches a visible element, marks
+// that element and returns a CSS selector for it with the locator that matched.
+// Locators are tried in order; one matching a single element wins over a better
+// ranked one matching several.
+func locate(ctx context.Context, a models.SnapshotAction, marker string) (string, models.Locator, error) {
+	locators := actionLocators(a)
+	if len(locators) == 0 {
+		return "", models.Locator{}, fmt.Errorf("action has no locator")
+	}
+
+	locateCtx, cancel := context.WithTimeout(ctx, locateTimeout)
+	defer cancel()
+	for {
+		if selector, index, err := resolveLocators(locateCtx, locators, marker); err == nil && index >= 0 {
+			return selector, locators[index], nil
+		}
+		select {
+		case <-locateCtx.Done():
+			if ctx.Err() != nil {
+				return "", models.Locator{}, ctx.Err()
+			}
+			return "", models.Locator{}, fmt.Errorf("no visible element after %v for %s", locateTimeout, describeLocators(locators))
+		case <-time.After(locatePoll):
+		}
+	}
+}
+
+// resolveLocators checks the locators once, marking the element of the first that
+// matches. It returns a CSS selector for the marked element and the index of the
+// locator (-1 if none matched).
+func resolveLocators(ctx context.Context, locators []models.Locator, marker string) (string, int, error) {
+	encoded, err := json.Marshal(locators)
+	if err != nil {
+		return "", -1, fmt.Errorf("failed to encode locators: %w", err)
+	}
+	markerJSON, _ := json.Marshal(marker)
+	script := fmt.Sprintf("(function() {\n%s\nreturn resolveLocators(%s, %s);\n})()", locatorFunctions, encoded, markerJSON)
+
+	var result locateResult
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &result)); err != nil {
+		return "", -1, err
+	}
+	return fmt.Sprintf("[%s=%q]", targetAttribute, marker), result.Index, nil
+}
+
+func describeLocators(locators []models.Locator) string {
+	described := make([]string, 0, len(locators))
+	for _, l := range locators {
+		described = append(described, describeLocator(l))
+	}
+	return strings.Join(described, ", ")
+}
+
+// locatorFunctions is the JavaScript shared by the recorder, which builds the
+// locators of an element, and the replay, which resolves them. Keep both halves
+// in sync: a locator is only useful if the replay finds what the recorder saw.
+const locatorFunctions = `
+var TEST_ATTRIBUTES = ['data-testid', 'data-test-id', 'data-test', 'data-cy', 'data-qa'];
+var MAX_TEXT = 60;
+
+function normalizeText(s) {
+	return (s || '').replace(/\s+/g, ' ').trim();
+}
+
+function implicitRole(el) {
+	var tag = el.tagName.toLowerCase();
+	var type = (el.getAttribute('type') || '').toLowerCase();
+	switch (tag) {
+	case 'a': return el.hasAttribute('href') ? 'link' : '';
+	case 'button': return 'button';
+	case 'select': return 'combobox';
+	case 'textarea': return 'textbox';
+	case 'h1': case 'h2': case 'h3': case 'h4': case 'h5': case 'h6': return 'heading';
+	case 'img': return 'img';
+	case 'input':
+		if (type === 'checkbox') return 'checkbox';
+		if (type === 'radio') return 'radio';
+		if (type === 'submit' || type === 'button' || type === 'reset') return 'button';
+		if (type === 'hidden') return '';
+		return 'textbox';
+	}
+	return '';
+}
+
+function elementRole(el) {
+	return el.getAttribute('role') || implicitRole(el);
+}
+
+function accessibleName(el) {
+	var label = el.getAttribute('aria-label');
+	if (label) return normalizeText(label);
+	var labelledBy = el.getAttribute('aria-labelledby');
+	if (labelledBy) {
+		var parts = labelledBy.split(/\s+/).map(function(id) {
+			var ref = document.getElementById(id);
+			return ref ? ref.textContent : '';
+		});
+		return normalizeText(parts.join(' '));
+	}
+	if (el.labels && el.labels.length > 0) return normalizeText(el.labels[0].textContent);
+	var attr = el.getAttribute('alt') || el.getAttribute('placeholder') || el.getAttribute('title');
+	if (attr) return normalizeText(attr);
+	var tag = el.tagName.toLowerCase();
+	if (tag === 'input' || tag === 'select' || tag === 'textarea') return '';
+	return normalizeText(el.textContent);
+}
+
+function cssPath(el) {
+	var path = [];
+	while (el && el.nodeType === 1) {
+		var tag = el.tagName.toLowerCase();
+		var nth = 1;
+		var sib = el;
+		while ((sib = sib.previousElementSibling) != null) {
+			if (sib.tagName === el.tagName) nth++;
+		}
+		path.unshift(tag + (nth > 1 ? (':nth-of-type(' + nth + ')') : ''));
+		el = el.parentElement;
+	}
+	return path.join(' > ');
+}
+
+function xpathOf(el) {
+	var parts = [];
+	while (el && el.nodeType === 1) {
+		var index = 1;
+		var sib = el.previousElementSibling;
+		while (sib) {
+			if (sib.tagName === el.tagName) index++;
+			sib = sib.previousElementSibling;
+		}
+		parts.unshift(el.tagName.toLowerCase() + '[' + index + ']');
+		el = el.parentElement;
+	}
+	return '/' + parts.join('/');
+}
+
+// IDs that look generated (long numbers, framework prefixes) change between builds
+function stableID(id) {
+	return id && !/\d{3,}|^[0-9]|:|^(ember|react|mui|radix|headlessui)/i.test(id);
+}
+
+// getLocators returns the locators of an element, most robust first
+function getLocators(el) {
+	var locators = [];
+	for (var i = 0; i < TEST_ATTRIBUTES.length; i++) {
+		var value = el.getAttribute(TEST_ATTRIBUTES[i]);
+		if (value) {
+			locators.push({kind: 'testid', name: TEST_ATTRIBUTES[i], value: value});
+			break;
+		}
+	}
+	var role = elementRole(el);
+	var name = accessibleName(el);
+	if (role && name && name.length <= MAX_TEXT) {
+		locators.push({kind: 'role', value: role, name: name});
+	}
+	var text = normalizeText(el.textContent);
+	if (text && text.length <= MAX_TEXT && el.children.length === 0) {
+		locators.push({kind: 'text', value: text});
+	}
+	locators.push({kind: 'css', value: stableID(el.id) ? '#' + CSS.escape(el.id) : cssPath(el)});
+	locators.push({kind: 'xpath', value: xpathOf(el)});
+	return locators;
+}
+
+function findByLocator(l) {
+	var all;
+	try {
+		switch (l.kind) {
+		case 'testid':
+			return Array.from(document.querySelectorAll('[' + (l.name || 'data-testid') + '="' + CSS.escape(l.value) + '"]'));
+		case 'css':
+			return Array.from(document.querySelectorAll(l.value));
+		case 'xpath':
+			var snapshot = document.evaluate(l.value, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			var nodes = [];
+			for (var i = 0; i < snapshot.snapshotLength; i++) nodes.push(snapshot.snapshotItem(i));
+			return nodes;
+		case 'role':
+			all = Array.from(document.body.querySelectorAll('*'));
+			return all.filter(function(el) { return elementRole(el) === l.value && accessibleName(el) === l.name; });
+		case 'text':
+			all = Array.from(document.body.querySelectorAll('*'));
+			return all.filter(function(el) {
+				if (normalizeText(el.textContent) !== l.value) return false;
+				// The innermost element with the text
+				return !Array.from(el.children).some(function(child) { return normalizeText(child.textContent) === l.value; });
+			});
+		}
+	} catch (e) {
+		// Invalid selector or expression
+	}
+	return [];
+}
+
+function isVisible(el) {
+	if (!el || el.nodeType !== 1) return false;
+	var rect = el.getBoundingClientRect();
+	var style = window.getComputedStyle(el);
+	return rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none';
+}
+
+// resolveLocators marks the element of the first locator matching exactly one visible
+// element (or else the first matching any) and returns which locator matched
+function resolveLocators(locators, marker) {
+	Array.from(document.querySelectorAll('[` + targetAttribute + `]')).forEach(function(el) {
+		el.removeAttribute('` + targetAttribute + `');
+	});
+	var fallback = null;
+	for (var i = 0; i < locators.length; i++) {
+		var visible = findByLocator(locators[i]).filter(isVisible);
+		if (visible.length === 1) {
+			visible[0].setAttribute('` + targetAttribute + `', marker);
+			return {index: i, count: 1};
+		}
+		if (visible.length > 1 && fallback === null) {
+			fallback = {index: i, count: visible.length, el: visible[0]};
+		}
+	}
+	if (fallback) {
+		fallback.el.setAttribute('` + targetAttribute + `', marker);
+		return {index: fallback.index, count: fallback.count};
+	}
+	return {index: -1, count: 0};
+}
+`
@@ -0,0 +1,258 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"apiwatcher/internal/secrets"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Placeholder prefixes; a placeholder without one is a snapshot variable
+const (
+	envPrefix    = "env."    // {{env.NAME}}: environment variable
+	secretPrefix = "secret." // {{secret.name}}: secret store
+)
+
+// placeholderPattern matches {{name}}, {{env.NAME}} and {{secret.name}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// SecretSource looks up the secrets referenced by {{secret.name}} placeholders
+type SecretSource interface {
+	Get(name string) (string, error)
+}
+
+// HasPlaceholders reports whether a value contains a placeholder
+func HasPlaceholders(value string) bool {
+	return placeholderPattern.MatchString(value)
+}
+
+// SecretRef returns the placeholder referencing a stored secret
+func SecretRef(name string) string {
+	return "{{" + secretPrefix + name + "}}"
+}
+
+// resolver replaces the placeholders of a snapshot. Secrets are only looked up
+// (and the store opened) when a snapshot references one.
+type resolver struct {
+	variables  map[string]string
+	secrets    SecretSource
+	errors     []string
+	usedSecret bool // The last resolved value referenced a secret
+}
+
+// Resolve returns a copy of the snapshot with every placeholder in its URL and in
+// the URL, selector, value, pattern and locators of its actions replaced. Variable
+// values may themselves reference env and secret placeholders. A nil secrets
+// source opens the default secret store if needed. Actions that used a secret are
+// marked Sensitive so their values aren't logged.
+func Resolve(s *Snapshot, secretSource SecretSource) (*Snapshot, error) {
+	r := &resolver{variables: s.Variables, secrets: secretSource}
+
+	resolved := *s
+	resolved.URL = r.resolve(s.URL)
+	resolved.Actions = make([]models.SnapshotAction, len(s.Actions))
+	for i, a := range s.Actions {
+		sensitive := false
+		field := func(value string) string {
+			value = r.resolve(value)
+			sensitive = sensitive || r.usedSecret
+			return value
+		}
+		a.URL = field(a.URL)
+		a.Selector = field(a.Selector)
+		a.Value = field(a.Value)
+		a.Pattern = field(a.Pattern)
+		if len(a.Locators) > 0 {
+			locators := make([]models.Locator, len(a.Locators))
+			for j, l := range a.Locators {
+				l.Value = field(l.Value)
+				l.Name = field(l.Name)
+				locators[j] = l
+			}
+			a.Locators = locators
+		}
+		a.Sensitive = isPasswordInput(a) || sensitive
+		resolved.Actions[i] = a
+	}
+
+	if len(r.errors) > 0 {
+		return nil, fmt.Errorf("failed to resolve placeholders: %s", strings.Join(r.errors, "; "))
+	}
+	return &resolved, nil
+}
+
+// resolve replaces the placeholders of a value
+func (r *resolver) resolve(value string) string {
+	r.usedSecret = false
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	return placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(name, envPrefix) || strings.HasPrefix(name, secretPrefix) {
+			return r.lookup(name, match)
+		}
+		variable, exists := r.variables[name]
+		if !exists {
+			r.fail("undefined variable %s", match)
+			return match
+		}
+		// A variable may reference the environment or a secret, not another variable
+		return placeholderPattern.ReplaceAllStringFunc(variable, func(inner string) string {
+			innerName := placeholderPattern.FindStringSubmatch(inner)[1]
+			if strings.HasPrefix(innerName, envPrefix) || strings.HasPrefix(innerName, secretPrefix) {
+				return r.lookup(innerName, inner)
+			}
+			r.fail("variable %s references another variable %s", match, inner)
+			return inner
+		})
+	})
+}
+
+// lookup resolves an env. or secret. placeholder
+func (r *resolver) lookup(name, match string) string {
+	if env, ok := strings.CutPrefix(name, envPrefix); ok {
+		value, exists := os.LookupEnv(env)
+		if !exists {
+			r.fail("environment variable %s is not set", env)
+			return match
+		}
+		return value
+	}
+
+	secretName := strings.TrimPrefix(name, secretPrefix)
+	if r.secrets == nil {
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			r.fail("%v", err)
+			return match
+		}
+		r.secrets = store
+	}
+	value, err := r.secrets.Get(secretName)
+	if err != nil {
+		r.fail("%v", err)
+		return match
+	}
+	r.usedSecret = true
+	return value
+}
+
+func (r *resolver) fail(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	for _, existing := range r.errors {
+		if existing == message {
+			return
+		}
+	}
+	r.errors = append(r.errors, message)
+}
+
+// ==========================
+// Recorded Passwords
+// ==========================
+
+// PasswordInput is a recorded input into a password field that still holds the
+// typed value in clear
+type PasswordInput struct {
+	ActionIndex   int    `json:"action_index"` // Last input of the typing run
+	Selector      string `json:"selector"`
+	SuggestedName string `json:"suggested_name"` // Secret name to offer
+}
+
+// passwordHint matches selectors and labels of password fields, e.g. #password,
+// input[type="password"] or [aria-label="PIN"]
+var passwordHint = regexp.MustCompile(`(?i)pass(word|wd|code|phrase)?\b|pwd|\bpin\b|type=["']?password`)
+
+// isPasswordInput reports whether an action types a password. Actions recorded
+// before password fields were flagged Sensitive are recognized by their
+// selector, locators or label.
+func isPasswordInput(a models.SnapshotAction) bool {
+	if a.Sensitive {
+		return true
+	}
+	if a.Type != "input" && a.Type != "change" {
+		return false
+	}
+	if passwordHint.MatchString(a.Selector) || passwordHint.MatchString(a.AriaLabel) {
+		return true
+	}
+	for _, l := range a.Locators {
+		if passwordHint.MatchString(l.Value) || passwordHint.MatchString(l.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordInputs returns the recorded password inputs that don't reference a secret yet
+func (s *Snapshot) PasswordInputs() []PasswordInput {
+	var inputs []PasswordInput
+	for i, a := range s.Actions {
+		if a.Type != "input" || !isPasswordInput(a) || a.Value == "" || HasPlaceholders(a.Value) {
+			continue
+		}
+		// Every keystroke is recorded; report the run once, at its last input
+		if i+1 < len(s.Actions) && s.Actions[i+1].Type == "input" && s.Actions[i+1].Selector == a.Selector {
+			continue
+		}
+		inputs = append(inputs, PasswordInput{ActionIndex: i, Selector: a.Selector, SuggestedName: suggestSecretName(s.URL)})
+	}
+	return inputs
+}
+
+// ReplaceWithSecret replaces the typed value of the input run ending at index
+// with a reference to the secret name and returns the value, which the caller
+// has to store under that name. The run is collapsed into its last input so the
+// partially typed values are dropped too, and change events that carried the
+// value reference the secret as well.
+func (s *Snapshot) ReplaceWithSecret(index int, name string) (string, error) {
+	if err := secrets.ValidateName(name); err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(s.Actions) || s.Actions[index].Type != "input" {
+		return "", fmt.Errorf("action %d is not an input", index)
+	}
+	last := s.Actions[index]
+	if HasPlaceholders(last.Value) {
+		return "", fmt.Errorf("action %d already references %s", index, last.Value)
+	}
+	if last.Value == redactedValue {
+		return "", fmt.Errorf("the password of action %d was left out of the snapshot, set the secret by hand", index)
+	}
+	secret := last.Value
+
+	start := index
+	for start > 0 && s.Actions[start-1].Type == "input" && s.Actions[start-1].Selector == last.Selector {
+		start--
+	}
+	last.Value = SecretRef(name)
+	last.Sensitive = true
+
+	actions := make([]models.SnapshotAction, 0, len(s.Actions)-(index-start))
+	actions = append(actions, s.Actions[:start]...)
+	actions = append(actions, last)
+	for _, a := range s.Actions[index+1:] {
+		if a.Type == "change" && a.Selector == last.Selector && (a.Value == secret || a.Value == redactedValue) {
+			a.Value = last.Value
+			a.Sensitive = true
+		}
+		actions = append(actions, a)
+	}
+	s.Actions = actions
+	return secret, nil
+}
+
+// suggestSecretName derives a secret name from the snapshot's host, e.g. shop_example_com_password
+func suggestSecretName(snapshotURL string) string {
+	host := snapshotURL
+	if parsed, err := url.Parse(snapshotURL); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+	host = strings.TrimPrefix(host, "www.")
+	name := regexp.MustCompile(`[^A-Za-z0-9]+`).ReplaceAllString(host, "_")
+	return strings.Trim(name, "_") + "_password"
+}
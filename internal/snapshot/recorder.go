@@ -32,6 +32,13 @@ func Record(targetURL string, snapshotName string) (*Snapshot, error) {
 // If stopChan is provided, recording stops when a value is sent to it.
 // If stopChan is nil, uses stdin (CLI mode).
 func RecordWithCallback(targetURL string, snapshotName string, stopChan chan bool) (*Snapshot, error) {
+	return RecordWithInserts(targetURL, snapshotName, stopChan, nil)
+}
+
+// RecordWithInserts is RecordWithCallback where actions sent to inserts (e.g. assertions
+// added from the GUI) are appended to the recording as they arrive. inserts is only
+// read in GUI mode (stopChan not nil).
+func RecordWithInserts(targetURL string, snapshotName string, stopChan chan bool, inserts <-chan models.SnapshotAction) (*Snapshot, error) {
 	// Get headless mode setting from config
 	headlessMode := config.IsHeadlessBrowserMode()
 
@@ -120,8 +127,11 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 		// doesn't expose DOM-level click/input events. Using runtime.AddBinding
 		// is the cleanest CDP-based approach for this use case.
 		chromedp.ActionFunc(func(ctx context.Context) error {
+			// locatorFunctions provides getLocators, which records the ranked
+			// locators the replay falls back through
 			_, exp, err := runtime.Evaluate(`
 				(function() {
+					` + locatorFunctions + `
 					function getSelector(el) {
 						if (!el) return "";
 						if (el.id) return "#" + el.id;
@@ -149,12 +159,33 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 						};
 					}
 
+					// Alt+click inserts an assertion instead of clicking: the element's
+					// text is asserted on replay, or its visibility if it has no text
+					function recordAssertion(el) {
+						var text = (el.innerText || "").trim().split("\n")[0].substring(0, 100);
+						recordAction(JSON.stringify({
+							type: text ? 'assert_text' : 'assert_visible',
+							selector: getSelector(el),
+							locators: getLocators(el),
+							value: text,
+							timestamp: Date.now(),
+							url: location.href
+						}));
+					}
+
 					// Capture click events (including suggestion clicks)
 					document.addEventListener('click', function(e) {
+						if (e.altKey) {
+							e.preventDefault();
+							e.stopImmediatePropagation();
+							recordAssertion(e.target);
+							return;
+						}
 						var info = getElementInfo(e.target);
 						recordAction(JSON.stringify({
 							type: 'click',
 							selector: info.selector,
+							locators: getLocators(e.target),
 							text: info.text,
 							classes: info.classes,
 							timestamp: Date.now(),
@@ -167,7 +198,9 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 						recordAction(JSON.stringify({
 							type: 'input',
 							selector: getSelector(e.target),
+							locators: getLocators(e.target),
 							value: e.target.value,
+							sensitive: e.target.type === 'password',
 							timestamp: Date.now(),
 							url: location.href
 						}));
@@ -175,12 +208,19 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 
 					// Capture mousedown events (for dropdown selections that use mousedown)
 					document.addEventListener('mousedown', function(e) {
+						if (e.altKey) {
+							// Part of an Alt+click assertion, keep it from the page
+							e.preventDefault();
+							e.stopImmediatePropagation();
+							return;
+						}
 						var info = getElementInfo(e.target);
 						// Only record mousedown if it looks like it could be a dropdown item
 						if (info.text || info.ariaLabel || e.target.getAttribute('role') === 'option') {
 							recordAction(JSON.stringify({
 								type: 'mousedown',
 								selector: info.selector,
+								locators: getLocators(e.target),
 								text: info.text,
 								ariaLabel: info.ariaLabel,
 								timestamp: Date.now(),
@@ -207,7 +247,9 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 						recordAction(JSON.stringify({
 							type: 'change',
 							selector: getSelector(e.target),
+							locators: getLocators(e.target),
 							value: e.target.value,
+							sensitive: e.target.type === 'password',
 							timestamp: Date.now(),
 							url: location.href
 						}));
@@ -219,6 +261,7 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 							recordAction(JSON.stringify({
 								type: 'keydown',
 								selector: getSelector(e.target),
+								locators: getLocators(e.target),
 								key: e.key,
 								timestamp: Date.now(),
 								url: location.href
@@ -241,22 +284,40 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 	}
 
 	fmt.Printf("\n[RECORDER] Chrome opened for %s\n", targetURL)
-	fmt.Println("[RECORDER] Perform the actions in the opened browser window.")
+	fmt.Println("[RECORDER] Perform the actions in the opened browser window (Alt+click an element to assert its text).")
 
 	// Wait for stop signal
+	var cliReader *bufio.Reader
 	if stopChan != nil {
 		// GUI mode: wait for signal from channel
 		fmt.Println("[RECORDER] Waiting for GUI signal to stop recording...")
-		cancelled := <-stopChan
-		if cancelled {
-			return nil, fmt.Errorf("recording cancelled by user")
+	wait:
+		for {
+			select {
+			case action := <-inserts:
+				if action.Timestamp == 0 {
+					action.Timestamp = time.Now().UnixMilli()
+				}
+				if action.Selector != "" && len(action.Locators) == 0 && action.Type != AssertCount {
+					action.Locators = elementLocators(ctx, action.Selector)
+				}
+				actionsMu.Lock()
+				actions = append(actions, action)
+				actionsMu.Unlock()
+				log.Printf("[RECORDER] Inserted %s action", action.Type)
+			case cancelled := <-stopChan:
+				if cancelled {
+					return nil, fmt.Errorf("recording cancelled by user")
+				}
+				break wait
+			}
 		}
 	} else {
 		// CLI mode: wait for Enter key
 		fmt.Println("[RECORDER] When finished press ENTER here to capture and save the snapshot (or type 'cancel').")
 		fmt.Print("> ")
-		inputReader := bufio.NewReader(os.Stdin)
-		line, _ := inputReader.ReadString('\n')
+		cliReader = bufio.NewReader(os.Stdin)
+		line, _ := cliReader.ReadString('\n')
 		line = strings.TrimSpace(line)
 		if strings.ToLower(line) == "cancel" {
 			return nil, fmt.Errorf("recording cancelled by user")
@@ -275,10 +336,38 @@ func RecordWithCallback(targetURL string, snapshotName string, stopChan chan boo
 		CreatedAt: time.Now(),
 	}
 
-	// Save snapshot to disk
-	if err := SaveToDisk(s); err != nil {
-		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	// The CLI asks before saving. The GUI asks once the recording stopped
+	// (App.FinishRecording), so passwords are saved redacted until the user
+	// stored them as secrets or chose to keep them (RestoreRedacted).
+	if cliReader != nil {
+		offerPasswordSecrets(s, cliReader)
+		if err := SaveVersion(s, "Recorded", ""); err != nil {
+			return nil, fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		return s, nil
 	}
 
+	saved := redactPasswords(s)
+	if err := SaveVersion(saved, "Recorded", ""); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	s.Version = saved.Version
 	return s, nil
 }
+
+// elementLocators returns the locators of the element a selector matches on the
+// recorded page (none if it matches nothing), so assertions added from the GUI
+// get the same fallbacks as the ones recorded by Alt+click
+func elementLocators(ctx context.Context, selector string) []models.Locator {
+	encoded, _ := json.Marshal(selector)
+	script := fmt.Sprintf("(function() {\n%s\nvar el = document.querySelector(%s);\nreturn el ? getLocators(el) : [];\n})()", locatorFunctions, encoded)
+
+	evalCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var locators []models.Locator
+	if err := chromedp.Run(evalCtx, chromedp.Evaluate(script, &locators)); err != nil {
+		log.Printf("[RECORDER] Failed to read locators of %s: %v", selector, err)
+		return nil
+	}
+	return locators
+}
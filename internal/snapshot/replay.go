@@ -2,10 +2,15 @@ package snapshot
 
 import (
 	"apiwatcher/internal/config"
+	"apiwatcher/internal/har"
 	"apiwatcher/internal/models"
+	"apiwatcher/internal/netcapture"
+	"apiwatcher/internal/tracing"
+	"apiwatcher/internal/wait"
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,10 +20,27 @@ import (
 
 // ReplayResult holds the result of a snapshot replay including any API errors detected
 type ReplayResult struct {
-	SnapshotID string          // The snapshot ID
-	Success    bool            // Whether replay completed without errors
-	APIErrors  []*APIErrorInfo // List of API errors detected during replay
-	Duration   time.Duration   // Time taken to complete replay
+	SnapshotID string            // The snapshot ID
+	Version    int               // Snapshot version that ran (0 = saved before versioning)
+	Success    bool              // Whether replay completed without API errors or failed required steps
+	APIErrors  []*APIErrorInfo   // List of API errors detected during replay
+	Duration   time.Duration     // Time taken to complete replay
+	HAR        *har.HAR          // The whole session (only when recorded)
+	Steps      []StepResult      // Outcome of every replayed action
+	Failures   []*FailureCapture // Screenshot and DOM captured at failed required steps
+}
+
+// Default waits replacing the former fixed sleeps: pages and the requests an
+// action triggers have to settle, but a page that never goes idle isn't failed
+var (
+	defaultPageWait   = wait.NetworkIdle(500*time.Millisecond, 10*time.Second)
+	defaultActionWait = wait.NetworkIdle(300*time.Millisecond, 5*time.Second)
+)
+
+// ReplayOptions configures a replay
+type ReplayOptions struct {
+	RecordHAR bool         // Record every request of the session as a HAR (ReplayResult.HAR)
+	Secrets   SecretSource // Resolves {{secret.name}} placeholders (nil = the default secret store)
 }
 
 // APIErrorInfo holds information about a detected API error
@@ -28,23 +50,50 @@ type APIErrorInfo struct {
 	Timestamp  time.Time // When the error was detected
 }
 
-// Replay runs a saved snapshot in Chrome and returns an error (backward compatible).
+// Replay runs a saved snapshot in Chrome and returns an error if it couldn't be
+// completed or a required step failed (backward compatible).
 // For detailed error information, use ReplayWithResult instead.
 func Replay(s *Snapshot) error {
-	_, err := ReplayWithResult(s)
-	return err
+	result, err := ReplayWithResult(s)
+	if err != nil {
+		return err
+	}
+	if failed := result.FailedSteps(); len(failed) > 0 {
+		return fmt.Errorf("%d required step(s) failed, first: %s", len(failed), failed[0].Describe())
+	}
+	return nil
 }
 
 // ReplayWithResult runs a saved snapshot in Chrome and returns detailed result information
 // including any API errors detected during the replay.
 func ReplayWithResult(s *Snapshot) (*ReplayResult, error) {
+	return ReplayWithOptions(context.Background(), s, ReplayOptions{})
+}
+
+// ReplayWithOptions is ReplayWithResult with a parent context and options. Captured API
+// responses are traced as child spans of the span carried by ctx, if any.
+func ReplayWithOptions(parentCtx context.Context, s *Snapshot, options ReplayOptions) (*ReplayResult, error) {
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
 	startTime := time.Now()
 	result := &ReplayResult{
 		SnapshotID: s.ID,
+		Version:    s.Version,
 		Success:    true,
 		APIErrors:  make([]*APIErrorInfo, 0),
 	}
 
+	// Fill in variables, environment variables and secrets before starting Chrome
+	resolved, err := Resolve(s, options.Secrets)
+	if err != nil {
+		log.Printf("[SNAPSHOT] ❌ %v\n", err)
+		result.Success = false
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+	s = resolved
+
 	// Get headless mode setting from config
 	headlessMode := config.IsHeadlessBrowserMode()
 
@@ -56,16 +105,46 @@ func ReplayWithResult(s *Snapshot) (*ReplayResult, error) {
 		chromedp.Flag("start-maximized", !headlessMode), // Only maximize if not headless
 	)
 
-	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(parentCtx, opts...)
 	defer cancelAlloc()
 
 	ctx, cancelCtx := chromedp.NewContext(allocCtx)
 	defer cancelCtx()
 
+	// Record the session; the HAR is built however the replay ends. Request and
+	// response bodies exchanged once a password or secret was typed are left out.
+	var capture *netcapture.Recorder
+	var sensitiveSince time.Time
+	if options.RecordHAR {
+		capture = netcapture.NewRecorder()
+		defer func() {
+			capture.LoadBodies(ctx)
+			requests := capture.Requests()
+			if !sensitiveSince.IsZero() {
+				har.DropBodies(requests, sensitiveSince)
+			}
+			result.HAR = har.Build(s.URL, startTime, requests)
+		}()
+	}
+
 	// Listen for network responses to catch API errors (async to avoid blocking)
 	var apiErrorsMu sync.Mutex
+	responses := &responseLog{}
+	tracker := wait.NewTracker()
+	spans := tracing.NewNetworkRecorder(tracing.SpanFromContext(parentCtx))
 	chromedp.ListenTarget(ctx, func(ev any) {
+		if capture != nil {
+			capture.Handle(ev)
+		}
+		tracker.Handle(ev)
+		if ev, ok := ev.(*network.EventRequestWillBeSent); ok {
+			spans.RequestSent(ev)
+		}
 		if ev, ok := ev.(*network.EventResponseReceived); ok {
+			responses.add(ev.Response.URL, int(ev.Response.Status))
+			if !config.IsStaticAsset(ev.Response.URL) {
+				spans.ResponseReceived(ev)
+			}
 			if ev.Response.Status >= 400 {
 				apiURL := ev.Response.URL
 				if !config.IsStaticAsset(apiURL) {
@@ -88,23 +167,28 @@ func ReplayWithResult(s *Snapshot) (*ReplayResult, error) {
 	runCtx, cancelRun := context.WithTimeout(ctx, 120*time.Second) // longer if needed
 	defer cancelRun()
 
-	log.Printf("[SNAPSHOT] 🎬 Starting replay for %s (ID: %s)\n", s.URL, s.ID)
+	log.Printf("[SNAPSHOT] 🎬 Starting replay for %s (ID: %s, version %d)\n", s.URL, s.ID, s.Version)
 
 	// Navigate to the initial URL
 	log.Printf("[SNAPSHOT] 🌐 Navigating to initial URL: %s\n", s.URL)
-	if err := chromedp.Run(runCtx,
+	err = chromedp.Run(runCtx,
+		network.Enable(),
 		chromedp.Navigate(s.URL),
 		chromedp.WaitVisible("body", chromedp.ByQuery),
-		chromedp.Sleep(5000*time.Millisecond),
-	); err != nil {
+	)
+	if err == nil {
+		err = waitForPage(runCtx, tracker, s.Wait, 0)
+	}
+	if err != nil {
 		log.Printf("[SNAPSHOT] ❌ Initial navigation failed: %v\n", err)
+		result.captureFailure(ctx, -1, models.SnapshotAction{Type: "navigate", URL: s.URL}, err)
 		result.Duration = time.Since(startTime)
 		return result, fmt.Errorf("initial navigation failed: %w", err)
 	}
 	log.Printf("[SNAPSHOT] ✅ Initial page loaded successfully\n")
 
 	originalActionCount := len(s.Actions)
-	var filteredActions = PreprocessActions(s.Actions)
+	filteredActions, actionIndexes := preprocessActions(s.Actions)
 	if originalActionCount != len(filteredActions) {
 		log.Printf("[SNAPSHOT] 📋 Preprocessed actions: %d -> %d (filtered %d duplicate inputs)\n",
 			originalActionCount, len(filteredActions), originalActionCount-len(filteredActions))
@@ -114,96 +198,208 @@ func ReplayWithResult(s *Snapshot) (*ReplayResult, error) {
 
 	// Replay all actions
 	for i, a := range filteredActions {
-		switch a.Type {
-		case "navigate":
-			if a.URL != "" {
-				log.Printf("[SNAPSHOT] 🔄 Action %d/%d: Navigate to %s\n", i+1, len(filteredActions), a.URL)
-				if err := chromedp.Run(runCtx,
-					chromedp.Navigate(a.URL),
-					chromedp.WaitVisible("body", chromedp.ByQuery),
-				); err != nil {
-					log.Printf("[SNAPSHOT] ❌ Navigation failed on action %d: %v\n", i+1, err)
-				} else {
-					log.Printf("[SNAPSHOT] ✅ Navigation successful\n")
-				}
-			}
-		case "click", "mousedown":
-			if a.Selector != "" {
-				actionType := "Click"
-				if a.Type == "mousedown" {
-					actionType = "MouseDown (dropdown selection)"
-				}
-				desc := a.Selector
-				if a.Text != "" {
-					desc = fmt.Sprintf("%s (%s)", a.Selector, a.Text)
-				}
-				log.Printf("[SNAPSHOT] 🖱️  Action %d/%d: %s on '%s'\n", i+1, len(filteredActions), actionType, desc)
-				if err := chromedp.Run(runCtx,
-					chromedp.WaitVisible(a.Selector, chromedp.ByQuery),
-					chromedp.ScrollIntoView(a.Selector, chromedp.ByQuery),
-					chromedp.Click(a.Selector, chromedp.ByQuery),
-				); err != nil {
-					log.Printf("[SNAPSHOT] ❌ %s failed on action %d: %v\n", actionType, i+1, err)
-				} else {
-					log.Printf("[SNAPSHOT] ✅ %s successful\n", actionType)
-				}
-			}
-		case "input":
-			if a.Selector != "" {
-				log.Printf("[SNAPSHOT] ⌨️  Action %d/%d: Input text into '%s'\n", i+1, len(filteredActions), a.Selector)
-				if err := chromedp.Run(runCtx,
-					chromedp.WaitVisible(a.Selector, chromedp.ByQuery),
-					chromedp.ScrollIntoView(a.Selector, chromedp.ByQuery),
-					chromedp.Focus(a.Selector, chromedp.ByQuery),
-					chromedp.SendKeys(a.Selector, a.Value, chromedp.ByQuery),
-				); err != nil {
-					log.Printf("[SNAPSHOT] ❌ Input failed on action %d: %v\n", i+1, err)
-				} else {
-					log.Printf("[SNAPSHOT] ✅ Input successful\n")
-				}
-			}
-		case "change":
-			if a.Selector != "" {
-				log.Printf("[SNAPSHOT] 📝 Action %d/%d: Change '%s' to '%s'\n", i+1, len(filteredActions), a.Selector, a.Value)
-				// Skip change actions - they are usually redundant with input actions
-				// and can cause hangs with custom form components
-				log.Printf("[SNAPSHOT] ⚠️  Skipping change action (use input actions instead)\n")
-			}
-		case "keydown":
-			if a.Selector != "" {
-				log.Printf("[SNAPSHOT] ⌨️  Action %d/%d: Key press '%s' on '%s'\n", i+1, len(filteredActions), a.Key, a.Selector)
-				if err := chromedp.Run(runCtx,
-					chromedp.Focus(a.Selector, chromedp.ByQuery),
-					chromedp.SendKeys(a.Selector, a.Key, chromedp.ByQuery),
-				); err != nil {
-					log.Printf("[SNAPSHOT] ❌ Keydown failed on action %d: %v\n", i+1, err)
-				} else {
-					log.Printf("[SNAPSHOT] ✅ Keydown successful\n")
-				}
+		stepStart := time.Now()
+		if a.Sensitive && sensitiveSince.IsZero() {
+			sensitiveSince = stepStart
+		}
+		mark := tracker.Mark()
+		status, locator, err := runAction(runCtx, i, len(filteredActions), a, responses)
+		if status == StepPassed {
+			if err = waitAfterAction(runCtx, tracker, a, s.Wait, mark); err != nil {
+				status = StepFailed
 			}
-		default:
-			log.Printf("[SNAPSHOT] ⚠️  Action %d/%d: Unknown type '%s', skipping\n", i+1, len(filteredActions), a.Type)
 		}
-
-		// Small delay between actions
-		_ = chromedp.Run(runCtx, chromedp.Sleep(500*time.Millisecond))
+		result.recordStep(ctx, actionIndexes[i], a, status, locator, err, time.Since(stepStart))
 	}
 
 	// Set duration and success flag
 	result.Duration = time.Since(startTime)
-	if len(result.APIErrors) > 0 {
-		result.Success = false
+	failedSteps := len(result.FailedSteps())
+	result.Success = len(result.APIErrors) == 0 && failedSteps == 0
+	switch {
+	case failedSteps > 0:
+		log.Printf("[SNAPSHOT] ❌ Replay completed for %s (ID: %s) with %d of %d required step(s) failed and %d API errors\n",
+			s.URL, s.ID, failedSteps, len(result.Steps), len(result.APIErrors))
+	case len(result.APIErrors) > 0:
 		log.Printf("[SNAPSHOT] ⚠️  Replay completed for %s (ID: %s) with %d API errors detected\n", s.URL, s.ID, len(result.APIErrors))
-	} else {
-		result.Success = true
+	default:
 		log.Printf("[SNAPSHOT] 🎉 Replay completed successfully for %s (ID: %s) with no API errors\n", s.URL, s.ID)
 	}
 	return result, nil
 }
 
+// waitForPage waits for a page to load with the snapshot's wait strategy. By default
+// the network has to go idle, but a page that keeps polling is replayed anyway.
+func waitForPage(ctx context.Context, tracker *wait.Tracker, strategy *wait.Strategy, mark int) error {
+	if strategy != nil {
+		return tracker.Wait(ctx, strategy, mark)
+	}
+	if err := tracker.Wait(ctx, defaultPageWait, mark); err != nil && ctx.Err() == nil {
+		log.Printf("[SNAPSHOT] ⏳ %v, continuing\n", err)
+	}
+	return ctx.Err()
+}
+
+// waitAfterAction waits for the action's own wait strategy (which fails the step if it
+// isn't met) or, by default, for the requests the action triggered to settle.
+// pageWait is the snapshot's wait strategy, used after navigations.
+func waitAfterAction(ctx context.Context, tracker *wait.Tracker, a models.SnapshotAction, pageWait *wait.Strategy, mark int) error {
+	if a.Wait != nil {
+		log.Printf("[SNAPSHOT] ⏳ Waiting for %s\n", a.Wait)
+		if err := tracker.Wait(ctx, a.Wait, mark); err != nil {
+			log.Printf("[SNAPSHOT] ❌ Wait failed: %v\n", err)
+			return err
+		}
+		return nil
+	}
+	if a.Type == "navigate" {
+		return waitForPage(ctx, tracker, pageWait, mark)
+	}
+	if err := tracker.Wait(ctx, defaultActionWait, mark); err != nil && ctx.Err() == nil {
+		log.Printf("[SNAPSHOT] ⏳ %v, continuing\n", err)
+	}
+	return ctx.Err()
+}
+
+// runAction replays a single action and returns StepPassed, or StepSkipped for
+// actions that aren't replayed, with the locator that found the action's element.
+// Errors mean the step failed. responses are the API responses received so far
+// (for assert_api).
+func runAction(runCtx context.Context, i, total int, a models.SnapshotAction, responses *responseLog) (string, string, error) {
+	switch a.Type {
+	case "navigate":
+		if a.URL == "" {
+			return StepSkipped, "", nil
+		}
+		log.Printf("[SNAPSHOT] 🔄 Action %d/%d: Navigate to %s\n", i+1, total, a.URL)
+		if err := chromedp.Run(runCtx,
+			chromedp.Navigate(a.URL),
+			chromedp.WaitVisible("body", chromedp.ByQuery),
+		); err != nil {
+			log.Printf("[SNAPSHOT] ❌ Navigation failed on action %d: %v\n", i+1, err)
+			return StepFailed, "", err
+		}
+		log.Printf("[SNAPSHOT] ✅ Navigation successful\n")
+	case "click", "mousedown":
+		if len(actionLocators(a)) == 0 {
+			return StepSkipped, "", nil
+		}
+		actionType := "Click"
+		if a.Type == "mousedown" {
+			actionType = "MouseDown (dropdown selection)"
+		}
+		desc := a.Selector
+		if a.Text != "" {
+			desc = fmt.Sprintf("%s (%s)", a.Selector, a.Text)
+		}
+		log.Printf("[SNAPSHOT] 🖱️  Action %d/%d: %s on '%s'\n", i+1, total, actionType, desc)
+		selector, locator, err := locateElement(runCtx, i, a)
+		if err == nil {
+			err = chromedp.Run(runCtx,
+				chromedp.ScrollIntoView(selector, chromedp.ByQuery),
+				chromedp.Click(selector, chromedp.ByQuery),
+			)
+		}
+		if err != nil {
+			log.Printf("[SNAPSHOT] ❌ %s failed on action %d: %v\n", actionType, i+1, err)
+			return StepFailed, locator, err
+		}
+		log.Printf("[SNAPSHOT] ✅ %s successful\n", actionType)
+		return StepPassed, locator, nil
+	case "input":
+		if len(actionLocators(a)) == 0 {
+			return StepSkipped, "", nil
+		}
+		log.Printf("[SNAPSHOT] ⌨️  Action %d/%d: Input text into '%s'\n", i+1, total, a.Selector)
+		selector, locator, err := locateElement(runCtx, i, a)
+		if err == nil {
+			err = chromedp.Run(runCtx,
+				chromedp.ScrollIntoView(selector, chromedp.ByQuery),
+				chromedp.Focus(selector, chromedp.ByQuery),
+				chromedp.SendKeys(selector, a.Value, chromedp.ByQuery),
+			)
+		}
+		if err != nil {
+			log.Printf("[SNAPSHOT] ❌ Input failed on action %d: %v\n", i+1, err)
+			return StepFailed, locator, err
+		}
+		log.Printf("[SNAPSHOT] ✅ Input successful\n")
+		return StepPassed, locator, nil
+	case "change":
+		if a.Selector == "" {
+			return StepSkipped, "", nil
+		}
+		value := a.Value
+		if a.Sensitive {
+			value = "••••••"
+		}
+		log.Printf("[SNAPSHOT] 📝 Action %d/%d: Change '%s' to '%s'\n", i+1, total, a.Selector, value)
+		// Skip change actions - they are usually redundant with input actions
+		// and can cause hangs with custom form components
+		log.Printf("[SNAPSHOT] ⚠️  Skipping change action (use input actions instead)\n")
+		return StepSkipped, "", nil
+	case "keydown":
+		if len(actionLocators(a)) == 0 {
+			return StepSkipped, "", nil
+		}
+		log.Printf("[SNAPSHOT] ⌨️  Action %d/%d: Key press '%s' on '%s'\n", i+1, total, a.Key, a.Selector)
+		selector, locator, err := locateElement(runCtx, i, a)
+		if err == nil {
+			err = chromedp.Run(runCtx,
+				chromedp.Focus(selector, chromedp.ByQuery),
+				chromedp.SendKeys(selector, a.Key, chromedp.ByQuery),
+			)
+		}
+		if err != nil {
+			log.Printf("[SNAPSHOT] ❌ Keydown failed on action %d: %v\n", i+1, err)
+			return StepFailed, locator, err
+		}
+		log.Printf("[SNAPSHOT] ✅ Keydown successful\n")
+		return StepPassed, locator, nil
+	case ActionWait:
+		// The wait itself runs after the action, like any action's wait
+		log.Printf("[SNAPSHOT] ⏳ Action %d/%d: Wait\n", i+1, total)
+	case AssertExists, AssertVisible, AssertText, AssertURL, AssertCount, AssertAPI:
+		log.Printf("[SNAPSHOT] 🔎 Action %d/%d: %s\n", i+1, total, describeAssertion(a))
+		if err := runAssertion(runCtx, a, responses); err != nil {
+			log.Printf("[SNAPSHOT] ❌ Assertion failed on action %d: %v\n", i+1, err)
+			return StepFailed, "", err
+		}
+		log.Printf("[SNAPSHOT] ✅ Assertion passed\n")
+	default:
+		log.Printf("[SNAPSHOT] ⚠️  Action %d/%d: Unknown type '%s', skipping\n", i+1, total, a.Type)
+		return StepSkipped, "", nil
+	}
+	return StepPassed, "", nil
+}
+
+// locateElement finds the element of action i and returns a selector for it with a
+// description of the locator that matched
+func locateElement(ctx context.Context, i int, a models.SnapshotAction) (string, string, error) {
+	selector, locator, err := locate(ctx, a, strconv.Itoa(i))
+	if err != nil {
+		return "", "", err
+	}
+	described := describeLocator(locator)
+	if best := actionLocators(a)[0]; locator != best {
+		log.Printf("[SNAPSHOT] 🎯 Located element via fallback %s (%s didn't match uniquely)\n", described, describeLocator(best))
+	} else {
+		log.Printf("[SNAPSHOT] 🎯 Located element via %s\n", described)
+	}
+	return selector, described, nil
+}
+
 // PreprocessActions filters out intermediate input events
 func PreprocessActions(actions []models.SnapshotAction) []models.SnapshotAction {
+	filtered, _ := preprocessActions(actions)
+	return filtered
+}
+
+// preprocessActions is PreprocessActions that also returns the index in actions
+// of every kept action
+func preprocessActions(actions []models.SnapshotAction) ([]models.SnapshotAction, []int) {
 	var filtered []models.SnapshotAction
+	var indexes []int
 	for i := 0; i < len(actions); i++ {
 		a := actions[i]
 		// Go to end of array for input
@@ -219,11 +415,13 @@ func PreprocessActions(actions []models.SnapshotAction) []models.SnapshotAction
 				i = j
 			}
 			filtered = append(filtered, last)
+			indexes = append(indexes, i)
 			continue
 		}
 
 		// Keep non-input actions as is
 		filtered = append(filtered, a)
+		indexes = append(indexes, i)
 	}
-	return filtered
+	return filtered, indexes
 }
@@ -0,0 +1,230 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"apiwatcher/internal/wait"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ==========================
+// Playwright / Puppeteer Script Conversion
+// ==========================
+
+// A JS string in single, double or back quotes
+const (
+	quoted        = "'[^']*'|\"[^\"]*\"|`[^`]*`"
+	stringLiteral = "(" + quoted + ")"
+)
+
+// Statements the converter understands, one per line. Locator calls take a
+// string and optional options, e.g. page.getByRole('button', { name: 'Save' }).
+var (
+	scriptTitle    = regexp.MustCompile(`^(?:test|it)\(` + stringLiteral)
+	scriptGoto     = regexp.MustCompile(`^page\.goto\(` + stringLiteral)
+	scriptKeyboard = regexp.MustCompile(`^page\.keyboard\.press\(` + stringLiteral)
+	scriptTimeout  = regexp.MustCompile(`^page\.waitForTimeout\((\d+)\)`)
+	scriptPage     = regexp.MustCompile(`^page\.(click|dblclick|check|fill|type|press|waitForSelector)\(` + stringLiteral + `(?:\s*,\s*` + stringLiteral + `)?`)
+	scriptLocator  = regexp.MustCompile(`^page\.(locator|getByRole|getByText|getByTestId|getByLabel|getByPlaceholder)\(` + stringLiteral + `(?:\s*,\s*\{([^}]*)\})?\)(?:\.(?:first|last)\(\))?\.(click|dblclick|check|fill|type|pressSequentially|press|waitFor)\(` + stringLiteral + `?`)
+	scriptURL      = regexp.MustCompile(`^(?:expect\(page\)\.toHaveURL|page\.waitForURL)\((` + quoted + `|/.+/)\)`)
+	scriptExpect   = regexp.MustCompile(`^expect\(page\.(locator|getByRole|getByText|getByTestId|getByLabel|getByPlaceholder)\(` + stringLiteral + `(?:\s*,\s*\{([^}]*)\})?\)\)\.(toBeVisible|toHaveText|toContainText|toHaveCount)\((` + quoted + `|\d+)?\)`)
+	scriptName     = regexp.MustCompile(`name:\s*` + stringLiteral)
+)
+
+// ConvertScript converts a simple Playwright or Puppeteer script to a snapshot.
+// Each statement has to fit on one line: page.goto, clicks, fills and key presses
+// on page selectors or Playwright locators, waits and expect assertions. The
+// first page.goto becomes the snapshot URL. Lines calling page or expect that
+// can't be converted are reported as warnings.
+func ConvertScript(script string) (*Snapshot, []string, error) {
+	s := newImportedSnapshot("")
+	var warnings []string
+
+	for i, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "await ")
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		actions, err := convertStatement(s, line)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: %v, skipped", i+1, err))
+			continue
+		}
+		for _, a := range actions {
+			a.Sensitive = isPasswordInput(a)
+			if err := ValidateAction(a); err != nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: %v, skipped", i+1, err))
+				continue
+			}
+			s.Actions = append(s.Actions, a)
+		}
+	}
+
+	if s.URL == "" {
+		return nil, warnings, fmt.Errorf("script has no page.goto")
+	}
+	if s.Name == "" {
+		s.Name = s.URL
+	}
+	return s, warnings, nil
+}
+
+// convertStatement converts one line of a script. Lines that don't touch the page
+// convert to no action.
+func convertStatement(s *Snapshot, line string) ([]models.SnapshotAction, error) {
+	if m := scriptTitle.FindStringSubmatch(line); m != nil {
+		if s.Name == "" {
+			s.Name = unquote(m[1])
+		}
+		return nil, nil
+	}
+	if m := scriptGoto.FindStringSubmatch(line); m != nil {
+		if s.URL == "" {
+			s.URL = unquote(m[1])
+			return nil, nil
+		}
+		return []models.SnapshotAction{{Type: "navigate", URL: unquote(m[1])}}, nil
+	}
+	if m := scriptKeyboard.FindStringSubmatch(line); m != nil {
+		return keyAction(unquote(m[1]), []models.Locator{{Kind: LocatorCSS, Value: ":focus"}})
+	}
+	if m := scriptTimeout.FindStringSubmatch(line); m != nil {
+		delay, _ := strconv.Atoi(m[1])
+		return []models.SnapshotAction{{Type: ActionWait, Wait: &wait.Strategy{Kind: wait.KindFixed, DelayMs: delay}}}, nil
+	}
+	if m := scriptPage.FindStringSubmatch(line); m != nil {
+		locator := selectorLocator(unquote(m[2]))
+		return elementAction(m[1], locator, m[3])
+	}
+	if m := scriptLocator.FindStringSubmatch(line); m != nil {
+		locator, err := playwrightLocator(m[1], unquote(m[2]), m[3])
+		if err != nil {
+			return nil, err
+		}
+		return elementAction(m[4], locator, m[5])
+	}
+	if m := scriptURL.FindStringSubmatch(line); m != nil {
+		pattern := m[1]
+		if strings.HasPrefix(pattern, "/") {
+			pattern = pattern[1 : len(pattern)-1]
+		} else {
+			pattern = "^" + regexp.QuoteMeta(unquote(pattern)) + "$"
+		}
+		return []models.SnapshotAction{{Type: AssertURL, Pattern: pattern}}, nil
+	}
+	if m := scriptExpect.FindStringSubmatch(line); m != nil {
+		return expectAction(m[1], unquote(m[2]), m[3], m[4], m[5])
+	}
+
+	if strings.Contains(line, "page.") || strings.HasPrefix(line, "expect(") {
+		return nil, fmt.Errorf("unsupported statement %q", line)
+	}
+	return nil, nil
+}
+
+// elementAction converts a method called on an element
+func elementAction(method string, locator models.Locator, arg string) ([]models.SnapshotAction, error) {
+	locators := []models.Locator{locator}
+	selector := firstCSS(locators)
+	switch method {
+	case "click", "dblclick", "check":
+		return []models.SnapshotAction{{Type: "click", Selector: selector, Locators: locators}}, nil
+	case "fill", "type", "pressSequentially":
+		if arg == "" {
+			return nil, fmt.Errorf("%s without a value", method)
+		}
+		return []models.SnapshotAction{{Type: "input", Selector: selector, Value: unquote(arg), Locators: locators}}, nil
+	case "press":
+		return keyAction(unquote(arg), locators)
+	case "waitForSelector", "waitFor":
+		if selector == "" {
+			return nil, fmt.Errorf("waiting requires a CSS selector")
+		}
+		return []models.SnapshotAction{{Type: ActionWait, Wait: &wait.Strategy{Kind: wait.KindSelector, Selector: selector}}}, nil
+	}
+	return nil, fmt.Errorf("unsupported method %s", method)
+}
+
+func keyAction(key string, locators []models.Locator) ([]models.SnapshotAction, error) {
+	if !recordedKeys[key] {
+		return nil, fmt.Errorf("key %s isn't replayed", key)
+	}
+	return []models.SnapshotAction{{Type: "keydown", Key: key, Selector: firstCSS(locators), Locators: locators}}, nil
+}
+
+// expectAction converts an expect(locator) assertion. Assertions address elements
+// by CSS, so locators without a CSS equivalent only support text checks.
+func expectAction(method, value, options, matcher, arg string) ([]models.SnapshotAction, error) {
+	locator, err := playwrightLocator(method, value, options)
+	if err != nil {
+		return nil, err
+	}
+	selector := firstCSS([]models.Locator{locator})
+	if selector == "" {
+		if locator.Kind == LocatorText && matcher == "toBeVisible" {
+			return []models.SnapshotAction{{Type: AssertText, Value: locator.Value}}, nil
+		}
+		return nil, fmt.Errorf("%s on a %s locator can't be checked without a CSS selector", matcher, locator.Kind)
+	}
+
+	switch matcher {
+	case "toBeVisible":
+		return []models.SnapshotAction{{Type: AssertVisible, Selector: selector}}, nil
+	case "toHaveText", "toContainText":
+		return []models.SnapshotAction{{Type: AssertText, Selector: selector, Value: unquote(arg)}}, nil
+	default: // toHaveCount
+		return []models.SnapshotAction{{Type: AssertCount, Selector: selector, Value: arg}}, nil
+	}
+}
+
+// playwrightLocator converts a Playwright locator call
+func playwrightLocator(method, value, options string) (models.Locator, error) {
+	switch method {
+	case "getByRole":
+		name := scriptName.FindStringSubmatch(options)
+		if name == nil {
+			return models.Locator{}, fmt.Errorf("getByRole(%q) needs a name option", value)
+		}
+		return models.Locator{Kind: LocatorRole, Value: value, Name: unquote(name[1])}, nil
+	case "getByText":
+		return models.Locator{Kind: LocatorText, Value: value}, nil
+	case "getByTestId":
+		return models.Locator{Kind: LocatorCSS, Value: fmt.Sprintf("[data-testid=%q]", value)}, nil
+	case "getByLabel":
+		return models.Locator{Kind: LocatorCSS, Value: fmt.Sprintf("[aria-label=%q]", value)}, nil
+	case "getByPlaceholder":
+		return models.Locator{Kind: LocatorCSS, Value: fmt.Sprintf("[placeholder=%q]", value)}, nil
+	}
+	return selectorLocator(value), nil
+}
+
+// selectorLocator converts a Playwright or Puppeteer selector string
+func selectorLocator(selector string) models.Locator {
+	switch {
+	case strings.HasPrefix(selector, "text="):
+		return models.Locator{Kind: LocatorText, Value: strings.Trim(strings.TrimPrefix(selector, "text="), `"'`)}
+	case strings.HasPrefix(selector, "xpath="):
+		return models.Locator{Kind: LocatorXPath, Value: strings.TrimPrefix(selector, "xpath=")}
+	case strings.HasPrefix(selector, "//"):
+		return models.Locator{Kind: LocatorXPath, Value: selector}
+	case strings.HasPrefix(selector, "::-p-text(") && strings.HasSuffix(selector, ")"):
+		return models.Locator{Kind: LocatorText, Value: selector[len("::-p-text(") : len(selector)-1]}
+	case strings.HasPrefix(selector, "::-p-xpath(") && strings.HasSuffix(selector, ")"):
+		return models.Locator{Kind: LocatorXPath, Value: selector[len("::-p-xpath(") : len(selector)-1]}
+	case strings.HasPrefix(selector, "css="):
+		return models.Locator{Kind: LocatorCSS, Value: strings.TrimPrefix(selector, "css=")}
+	}
+	return models.Locator{Kind: LocatorCSS, Value: selector}
+}
+
+// unquote strips the quotes of a string literal matched by stringLiteral
+func unquote(literal string) string {
+	if len(literal) < 2 {
+		return literal
+	}
+	return literal[1 : len(literal)-1]
+}
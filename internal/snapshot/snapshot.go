@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"apiwatcher/internal/models"
+	"apiwatcher/internal/wait"
 	"time"
 )
 
@@ -12,6 +13,9 @@ type Snapshot struct {
 	ID        string                  `json:"id"`
 	URL       string                  `json:"url"`
 	Name      string                  `json:"name,omitempty"`
+	Version   int                     `json:"version,omitempty"` // Saved version (0 = saved before versioning)
 	Actions   []models.SnapshotAction `json:"actions"`
+	Wait      *wait.Strategy          `json:"wait,omitempty"`      // When a page counts as loaded (nil = network idle)
+	Variables map[string]string       `json:"variables,omitempty"` // Values of {{name}} placeholders
 	CreatedAt time.Time               `json:"created_at"`
 }
@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Step statuses
+const (
+	StepPassed  = "passed"  // The action was replayed
+	StepFailed  = "failed"  // The action errored (element missing, click failed, ...)
+	StepSkipped = "skipped" // The action isn't replayed (change events, unknown types, no selector)
+)
+
+// locationTimeout bounds reading the page URL after a step
+const locationTimeout = 2 * time.Second
+
+// StepResult is the outcome of a single replayed action
+type StepResult struct {
+	Index      int           // Index of the action in the snapshot
+	Type       string        // Action type (click, input, ...)
+	Selector   string        // Action selector
+	Locator    string        // Locator that found the element, e.g. role=button "Save"
+	Status     string        // StepPassed, StepFailed or StepSkipped
+	Error      string        // Why the step failed
+	Duration   time.Duration // Time taken by the step
+	URL        string        // Page URL after the step
+	Optional   bool          // A failed optional step doesn't fail the replay
+	ArtifactID string        // Screenshot and DOM captured when a required step failed
+}
+
+// Failed reports whether the step failed and wasn't optional
+func (s StepResult) Failed() bool {
+	return s.Status == StepFailed && !s.Optional
+}
+
+// Describe returns a one line description of the step and its error, if any
+func (s StepResult) Describe() string {
+	desc := fmt.Sprintf("Step %d (%s", s.Index+1, s.Type)
+	if s.Selector != "" {
+		desc += " " + s.Selector
+	}
+	desc += ")"
+	if s.Error != "" {
+		desc += ": " + s.Error
+	}
+	return desc
+}
+
+// FailedSteps returns the failed required steps
+func (r *ReplayResult) FailedSteps() []StepResult {
+	var failed []StepResult
+	for _, step := range r.Steps {
+		if step.Failed() {
+			failed = append(failed, step)
+		}
+	}
+	return failed
+}
+
+// recordStep adds the outcome of an action to the result and captures the page
+// state if a required step failed. ctx must be the chromedp tab context.
+func (r *ReplayResult) recordStep(ctx context.Context, index int, a models.SnapshotAction, status, locator string, err error, duration time.Duration) {
+	step := StepResult{
+		Index:    index,
+		Type:     a.Type,
+		Selector: a.Selector,
+		Locator:  locator,
+		Status:   status,
+		Duration: duration,
+		Optional: a.Optional,
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	locationCtx, cancel := context.WithTimeout(ctx, locationTimeout)
+	if chromedp.Run(locationCtx, chromedp.Location(&step.URL)) != nil {
+		step.URL = ""
+	}
+	cancel()
+
+	switch {
+	case step.Failed():
+		if failure := r.captureFailure(ctx, index, a, err); failure != nil {
+			step.ArtifactID = failure.ID
+		}
+	case status == StepFailed:
+		log.Printf("[SNAPSHOT] ⏭️  Optional step %d failed, continuing\n", index+1)
+	}
+	r.Steps = append(r.Steps, step)
+}
@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -18,7 +19,14 @@ func dirPath() string {
 	return filepath.Join(home, ".url-checker", "snapshots")
 }
 
+// SaveToDisk saves a snapshot as a new version (see SaveVersion)
 func SaveToDisk(s *Snapshot) error {
+	return SaveVersion(s, "Saved", "")
+}
+
+// writeCurrent writes the current version of a snapshot, the one replays use. It
+// may hold passwords typed in clear, so only its owner can read it.
+func writeCurrent(s *Snapshot) error {
 	dir := dirPath()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -28,7 +36,7 @@ func SaveToDisk(s *Snapshot) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, data, 0644)
+	return os.WriteFile(filename, data, 0600)
 }
 
 func LoadForURL(url string) ([]*Snapshot, error) {
@@ -108,9 +116,28 @@ func LoadByID(id string) (*Snapshot, error) {
 	return &s, nil
 }
 
-// DeleteFromDisk deletes a snapshot file by its ID
+// LoadByIDs loads the snapshots with the given IDs, or every snapshot if ids is empty
+func LoadByIDs(ids []string) ([]*Snapshot, error) {
+	if len(ids) == 0 {
+		return LoadAll()
+	}
+	snaps := make([]*Snapshot, 0, len(ids))
+	for _, id := range ids {
+		s, err := LoadByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot %s: %w", id, err)
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps, nil
+}
+
+// DeleteFromDisk deletes a snapshot file and its versions by its ID
 func DeleteFromDisk(id string) error {
 	dir := dirPath()
 	filename := filepath.Join(dir, id+".json")
-	return os.Remove(filename)
+	if err := os.Remove(filename); err != nil {
+		return err
+	}
+	return os.RemoveAll(versionsDir(id))
 }
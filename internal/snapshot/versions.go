@@ -0,0 +1,460 @@
+package snapshot
+
+import (
+	"apiwatcher/internal/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedValue replaces passwords typed in clear in stored versions, and in GUI
+// recordings until the user stores them as secrets or keeps them; only the
+// current snapshot keeps them
+const redactedValue = "[redacted]"
+
+// Version describes a saved version of a snapshot
+type Version struct {
+	Number  int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	Author  string    `json:"author,omitempty"` // User that saved it
+	Change  string    `json:"change,omitempty"` // What changed, e.g. "Updated action 3"
+	Note    string    `json:"note,omitempty"`   // Why, in the author's words
+	Actions int       `json:"actions"`
+}
+
+// versionFile is a stored version with the snapshot as it was saved
+type versionFile struct {
+	Version
+	Snapshot *Snapshot `json:"snapshot"`
+}
+
+// versionMutex serializes version number allocation
+var versionMutex sync.Mutex
+
+func versionsDir(id string) string {
+	return filepath.Join(dirPath(), "versions", id)
+}
+
+func versionPath(id string, number int) string {
+	return filepath.Join(versionsDir(id), fmt.Sprintf("v%d.json", number))
+}
+
+// SaveVersion saves a snapshot as its next version, keeping the previous ones.
+// change says what changed and note why. A snapshot saved before versioning
+// existed first gets its file on disk stored as version 1.
+func SaveVersion(s *Snapshot, change, note string) error {
+	// Allocating the number and writing the version are one step, so concurrent
+	// saves (e.g. the recorder and an edit) can't both take the same number
+	versionMutex.Lock()
+	defer versionMutex.Unlock()
+
+	versions, err := ListVersions(s.ID)
+	if err != nil {
+		return err
+	}
+	latest := 0
+	if len(versions) > 0 {
+		latest = versions[0].Number
+	} else if previous, err := LoadByID(s.ID); err == nil {
+		previous.Version = 1
+		initial := Version{Number: 1, SavedAt: previous.CreatedAt, Change: "Saved before versioning", Actions: len(previous.Actions)}
+		if err := writeVersion(previous, initial); err != nil {
+			return err
+		}
+		latest = 1
+	}
+
+	s.Version = latest + 1
+	version := Version{
+		Number:  s.Version,
+		SavedAt: time.Now(),
+		Author:  currentAuthor(),
+		Change:  change,
+		Note:    strings.TrimSpace(note),
+		Actions: len(s.Actions),
+	}
+	if err := writeVersion(s, version); err != nil {
+		return err
+	}
+	return writeCurrent(s)
+}
+
+func writeVersion(s *Snapshot, version Version) error {
+	if err := os.MkdirAll(versionsDir(s.ID), 0700); err != nil {
+		return fmt.Errorf("failed to create version directory: %w", err)
+	}
+	data, err := json.MarshalIndent(versionFile{Version: version, Snapshot: redactPasswords(s)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot version: %w", err)
+	}
+	if err := os.WriteFile(versionPath(s.ID, version.Number), data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot version: %w", err)
+	}
+	return nil
+}
+
+// redactPasswords returns a copy of the snapshot without the passwords typed in
+// clear. Besides Sensitive actions this covers the password inputs of snapshots
+// recorded before actions were flagged (see isPasswordInput).
+func redactPasswords(s *Snapshot) *Snapshot {
+	redacted := *s
+	redacted.Actions = make([]models.SnapshotAction, len(s.Actions))
+	for i, a := range s.Actions {
+		if isPasswordInput(a) && a.Value != "" && !HasPlaceholders(a.Value) {
+			a.Value = redactedValue
+			a.Sensitive = true
+		}
+		redacted.Actions[i] = a
+	}
+	return &redacted
+}
+
+// RestoreRedacted puts back the passwords of s that were redacted, from clear, the
+// same snapshot before redaction (e.g. a recording saved redacted). Actions are
+// matched by index, so both must have the same actions.
+func RestoreRedacted(s, clear *Snapshot) int {
+	restored := 0
+	for i, a := range s.Actions {
+		if i >= len(clear.Actions) || a.Value != redactedValue {
+			continue
+		}
+		if c := clear.Actions[i]; c.Type == a.Type && c.Selector == a.Selector && c.Value != redactedValue {
+			s.Actions[i].Value = c.Value
+			restored++
+		}
+	}
+	return restored
+}
+
+func currentAuthor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// ListVersions returns the saved versions of a snapshot, newest first
+func ListVersions(id string) ([]Version, error) {
+	entries, err := os.ReadDir(versionsDir(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Version{}, nil
+		}
+		return nil, fmt.Errorf("failed to list snapshot versions: %w", err)
+	}
+
+	versions := []Version{}
+	for _, e := range entries {
+		number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(e.Name(), "v"), ".json"))
+		if err != nil || e.IsDir() {
+			continue
+		}
+		file, err := readVersion(id, number)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, file.Version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Number > versions[j].Number })
+	return versions, nil
+}
+
+// LoadVersion loads a snapshot as it was saved in a version
+func LoadVersion(id string, number int) (*Snapshot, error) {
+	file, err := readVersion(id, number)
+	if err != nil {
+		return nil, err
+	}
+	return file.Snapshot, nil
+}
+
+func readVersion(id string, number int) (*versionFile, error) {
+	raw, err := os.ReadFile(versionPath(id, number))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot %s has no version %d", id, number)
+		}
+		return nil, fmt.Errorf("failed to read snapshot version: %w", err)
+	}
+	var file versionFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot version: %w", err)
+	}
+	if file.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot version %d is empty", number)
+	}
+	return &file, nil
+}
+
+// Rollback restores the actions and settings of an earlier version. The restored
+// snapshot is saved as a new version, so the rollback can itself be undone.
+func Rollback(id string, number int, note string) (*Snapshot, error) {
+	editMutex.Lock()
+	defer editMutex.Unlock()
+
+	restored, err := LoadVersion(id, number)
+	if err != nil {
+		return nil, err
+	}
+	current, err := LoadByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if err := restorePasswords(restored, current); err != nil {
+		return nil, fmt.Errorf("can't roll back to version %d: %w", number, err)
+	}
+	if err := SaveVersion(restored, fmt.Sprintf("Rolled back to version %d", number), note); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// restorePasswords fills in the passwords redacted from a stored version with the
+// values the current snapshot types into the same fields
+func restorePasswords(restored, current *Snapshot) error {
+	for i, a := range restored.Actions {
+		if !a.Sensitive || a.Value != redactedValue {
+			continue
+		}
+		value := ""
+		for _, c := range current.Actions {
+			if isPasswordInput(c) && c.Type == a.Type && c.Selector == a.Selector && c.Value != "" && c.Value != redactedValue {
+				value = c.Value // The last one is what the field ends up holding
+			}
+		}
+		if value == "" {
+			return fmt.Errorf("the password typed by action %d isn't kept in the history", i+1)
+		}
+		restored.Actions[i].Value = value
+	}
+	return nil
+}
+
+// ==========================
+// Version Diffs
+// ==========================
+
+// Kinds of action changes
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionChanged = "changed" // Same position in the journey, different fields
+	ActionMoved   = "moved"   // Same action, different position
+)
+
+// FieldChange is a field whose value differs between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"` // JSON value (empty if unset)
+	To    string `json:"to"`
+}
+
+// ActionChange is an action that differs between two versions
+type ActionChange struct {
+	Kind      string                 `json:"kind"`
+	FromIndex int                    `json:"from_index"` // -1 if added
+	ToIndex   int                    `json:"to_index"`   // -1 if removed
+	From      *models.SnapshotAction `json:"from,omitempty"`
+	To        *models.SnapshotAction `json:"to,omitempty"`
+	Fields    []FieldChange          `json:"fields,omitempty"` // ActionChanged only
+}
+
+// Diff is what changed between two versions of a snapshot
+type Diff struct {
+	From    int            `json:"from"`
+	To      int            `json:"to"`
+	Fields  []FieldChange  `json:"fields,omitempty"` // Snapshot settings (name, URL, wait, variables)
+	Actions []ActionChange `json:"actions"`
+}
+
+// DiffVersions compares two versions of a snapshot action by action
+func DiffVersions(id string, from, to int) (*Diff, error) {
+	older, err := LoadVersion(id, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := LoadVersion(id, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := DiffSnapshots(older, newer)
+	diff.From, diff.To = from, to
+	return diff, nil
+}
+
+// DiffSnapshots compares two snapshots action by action. Actions are matched with
+// a longest common subsequence; an unmatched pair of the same type at the same
+// place is a change, an unmatched action found elsewhere a move.
+func DiffSnapshots(older, newer *Snapshot) *Diff {
+	diff := &Diff{From: older.Version, To: newer.Version, Actions: []ActionChange{}}
+	diff.Fields = append(diff.Fields, fieldChanges("name", older.Name, newer.Name)...)
+	diff.Fields = append(diff.Fields, fieldChanges("url", older.URL, newer.URL)...)
+	diff.Fields = append(diff.Fields, fieldChanges("wait", older.Wait, newer.Wait)...)
+	diff.Fields = append(diff.Fields, fieldChanges("variables", older.Variables, newer.Variables)...)
+
+	a, b := older.Actions, newer.Actions
+	keysA, keysB := actionKeys(a), actionKeys(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if keysA[i] == keysB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table; unmatched actions between two matches form a gap
+	var removed, added []int
+	flush := func() {
+		diff.Actions = append(diff.Actions, gapChanges(a, b, removed, added)...)
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && keysA[i] == keysB[j]:
+			flush()
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+
+	diff.Actions = detectMoves(diff.Actions, keysA, keysB)
+	return diff
+}
+
+// gapChanges pairs the removed and added actions of a gap by position: a pair of
+// the same type is a change, anything else is removed or added
+func gapChanges(a, b []models.SnapshotAction, removed, added []int) []ActionChange {
+	var changes []ActionChange
+	for k := 0; k < max(len(removed), len(added)); k++ {
+		switch {
+		case k < len(removed) && k < len(added) && a[removed[k]].Type == b[added[k]].Type:
+			from, to := a[removed[k]], b[added[k]]
+			changes = append(changes, ActionChange{
+				Kind: ActionChanged, FromIndex: removed[k], ToIndex: added[k],
+				From: &from, To: &to, Fields: actionFieldChanges(from, to),
+			})
+		default:
+			if k < len(removed) {
+				from := a[removed[k]]
+				changes = append(changes, ActionChange{Kind: ActionRemoved, FromIndex: removed[k], ToIndex: -1, From: &from})
+			}
+			if k < len(added) {
+				to := b[added[k]]
+				changes = append(changes, ActionChange{Kind: ActionAdded, FromIndex: -1, ToIndex: added[k], To: &to})
+			}
+		}
+	}
+	return changes
+}
+
+// detectMoves turns a removed action that was added unchanged elsewhere into a
+// single move, kept where it was removed
+func detectMoves(changes []ActionChange, keysA, keysB []string) []ActionChange {
+	for r := range changes {
+		if changes[r].Kind != ActionRemoved {
+			continue
+		}
+		for c := range changes {
+			if changes[c].Kind == ActionAdded && keysA[changes[r].FromIndex] == keysB[changes[c].ToIndex] {
+				changes[r].Kind = ActionMoved
+				changes[r].ToIndex = changes[c].ToIndex
+				changes[r].To = changes[c].To
+				changes[c].Kind = "" // Merged into the move
+				break
+			}
+		}
+	}
+	kept := changes[:0]
+	for _, change := range changes {
+		if change.Kind != "" {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// actionKeys returns a comparable encoding of every action, ignoring when it was recorded
+func actionKeys(actions []models.SnapshotAction) []string {
+	keys := make([]string, len(actions))
+	for i, a := range actions {
+		a.Timestamp = 0
+		data, _ := json.Marshal(a)
+		keys[i] = string(data)
+	}
+	return keys
+}
+
+// actionFieldChanges lists the JSON fields that differ between two actions
+func actionFieldChanges(from, to models.SnapshotAction) []FieldChange {
+	from.Timestamp, to.Timestamp = 0, 0
+	fieldsFrom, fieldsTo := jsonFields(from), jsonFields(to)
+
+	names := []string{}
+	for name := range fieldsFrom {
+		names = append(names, name)
+	}
+	for name := range fieldsTo {
+		if _, exists := fieldsFrom[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		if fieldsFrom[name] != fieldsTo[name] {
+			changes = append(changes, FieldChange{Field: name, From: fieldsFrom[name], To: fieldsTo[name]})
+		}
+	}
+	return changes
+}
+
+// jsonFields returns the JSON encoding of every field of an action
+func jsonFields(a models.SnapshotAction) map[string]string {
+	data, _ := json.Marshal(a)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	fields := make(map[string]string, len(raw))
+	for name, value := range raw {
+		fields[name] = string(value)
+	}
+	return fields
+}
+
+// fieldChanges compares a snapshot setting, returning a change if it differs
+func fieldChanges(field string, from, to any) []FieldChange {
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		if s := string(data); s != "null" && s != `""` && s != "{}" {
+			return s
+		}
+		return ""
+	}
+	if encodedFrom, encodedTo := encode(from), encode(to); encodedFrom != encodedTo {
+		return []FieldChange{{Field: field, From: encodedFrom, To: encodedTo}}
+	}
+	return nil
+}
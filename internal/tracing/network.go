@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// NetworkRecorder turns responses captured from chromedp network events into
+// client spans under a parent span. A nil recorder ignores all events.
+type NetworkRecorder struct {
+	parent   *Span
+	mutex    sync.Mutex
+	requests map[network.RequestID]sentRequest
+	offset   time.Duration // Wall clock minus browser monotonic clock
+}
+
+type sentRequest struct {
+	method string
+	start  time.Time
+}
+
+// NewNetworkRecorder returns a recorder for the given parent span, or nil while tracing is disabled
+func NewNetworkRecorder(parent *Span) *NetworkRecorder {
+	if parent == nil {
+		return nil
+	}
+	return &NetworkRecorder{parent: parent, requests: make(map[network.RequestID]sentRequest)}
+}
+
+// RequestSent remembers the method and wall clock start of a request
+func (r *NetworkRecorder) RequestSent(ev *network.EventRequestWillBeSent) {
+	if r == nil || ev.Request == nil {
+		return
+	}
+	start := time.Now()
+	if ev.WallTime != nil {
+		start = ev.WallTime.Time()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if ev.Timestamp != nil && ev.WallTime != nil {
+		r.offset = start.Sub(ev.Timestamp.Time())
+	}
+	r.requests[ev.RequestID] = sentRequest{method: ev.Request.Method, start: start}
+}
+
+// ResponseReceived records a span for the response, from request start until its headers arrived
+func (r *NetworkRecorder) ResponseReceived(ev *network.EventResponseReceived) {
+	if r == nil || ev.Response == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	sent, known := r.requests[ev.RequestID]
+	delete(r.requests, ev.RequestID)
+	end := time.Now()
+	if ev.Timestamp != nil && r.offset != 0 {
+		end = ev.Timestamp.Time().Add(r.offset)
+	}
+	r.mutex.Unlock()
+
+	start := sent.start
+	if !known {
+		start = end
+		if timing := ev.Response.Timing; timing != nil {
+			start = end.Add(-msDuration(timing.ReceiveHeadersEnd))
+		}
+	}
+
+	name := "HTTP"
+	if sent.method != "" {
+		name = "HTTP " + sent.method
+	}
+	span := r.parent.Child(name, KindClient, start)
+	span.SetAttribute("url.full", ev.Response.URL)
+	span.SetAttribute("http.response.status_code", ev.Response.Status)
+	span.SetAttribute("apiwatcher.resource_type", string(ev.Type))
+	if sent.method != "" {
+		span.SetAttribute("http.request.method", sent.method)
+	}
+	if ev.Response.Protocol != "" {
+		span.SetAttribute("network.protocol.name", ev.Response.Protocol)
+	}
+	if timing := ev.Response.Timing; timing != nil {
+		setTimingAttributes(span, timing)
+	}
+	if ev.Response.Status >= 400 {
+		span.SetError(fmt.Sprintf("HTTP %d", ev.Response.Status))
+	}
+	span.FinishAt(end)
+}
+
+// setTimingAttributes adds the phases of a response's resource timing in milliseconds.
+// Phases that didn't happen (e.g. DNS on a reused connection) are left out.
+func setTimingAttributes(span *Span, t *network.ResourceTiming) {
+	phase := func(key string, start, end float64) {
+		if start >= 0 && end >= start {
+			span.SetAttribute(key, end-start)
+		}
+	}
+	phase("apiwatcher.timing.dns_ms", t.DNSStart, t.DNSEnd)
+	phase("apiwatcher.timing.connect_ms", t.ConnectStart, t.ConnectEnd)
+	phase("apiwatcher.timing.tls_ms", t.SslStart, t.SslEnd)
+	phase("apiwatcher.timing.send_ms", t.SendStart, t.SendEnd)
+	phase("apiwatcher.timing.ttfb_ms", t.SendEnd, t.ReceiveHeadersEnd)
+	if t.ReceiveHeadersEnd >= 0 {
+		span.SetAttribute("apiwatcher.timing.headers_received_ms", t.ReceiveHeadersEnd)
+	}
+}
+
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
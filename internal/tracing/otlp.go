@@ -0,0 +1,324 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tracesPath    = "/v1/traces"
+	flushInterval = 5 * time.Second
+	maxBatchSize  = 256  // Spans per export request
+	maxQueueSize  = 4096 // Spans kept while the collector is unreachable; newer ones are dropped
+	scopeName     = "apiwatcher"
+)
+
+// Exporter sends finished spans in batches to an OTLP/HTTP collector using the JSON encoding
+type Exporter struct {
+	Endpoint    string            // Full traces URL, e.g. http://localhost:4318/v1/traces
+	ServiceName string            // service.name resource attribute
+	Headers     map[string]string // Extra request headers (e.g. authentication)
+	Client      *http.Client
+
+	mutex   sync.Mutex
+	pending []*Span
+	dropped int
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// NewExporter creates an exporter and starts its background flush loop.
+// endpoint is the collector base URL; /v1/traces is appended unless already present.
+func NewExporter(endpoint, serviceName string) *Exporter {
+	e := &Exporter{
+		Endpoint:    TracesEndpoint(endpoint),
+		ServiceName: serviceName,
+		Headers:     make(map[string]string),
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		flushCh:     make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+// TracesEndpoint returns the OTLP/HTTP traces URL for a collector base URL
+func TracesEndpoint(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if strings.HasSuffix(endpoint, tracesPath) {
+		return endpoint
+	}
+	return endpoint + tracesPath
+}
+
+// EndpointFromEnv returns the collector endpoint from the standard OpenTelemetry
+// environment variables, or "" if none is set
+func EndpointFromEnv() string {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// enqueue adds a finished span to the next batch
+func (e *Exporter) enqueue(s *Span) {
+	if e == nil {
+		return
+	}
+	e.mutex.Lock()
+	if len(e.pending) >= maxQueueSize {
+		e.dropped++
+		e.mutex.Unlock()
+		return
+	}
+	e.pending = append(e.pending, s)
+	full := len(e.pending) >= maxBatchSize
+	e.mutex.Unlock()
+
+	if full {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *Exporter) loop() {
+	defer close(e.doneCh)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flushCh:
+		case <-e.stopCh:
+			e.flushLogged(context.Background())
+			return
+		}
+		e.flushLogged(context.Background())
+	}
+}
+
+func (e *Exporter) flushLogged(ctx context.Context) {
+	if err := e.Flush(ctx); err != nil {
+		log.Printf("[TRACING] ⚠️  %v", err)
+	}
+}
+
+// Flush exports all queued spans. When a batch fails, it and the spans behind it
+// are queued again for the next flush, within the queue limit.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mutex.Lock()
+	spans := e.pending
+	e.pending = nil
+	dropped := e.dropped
+	e.dropped = 0
+	e.mutex.Unlock()
+
+	if dropped > 0 {
+		log.Printf("[TRACING] ⚠️  Dropped %d span(s), export queue was full", dropped)
+	}
+
+	for len(spans) > 0 {
+		n := min(len(spans), maxBatchSize)
+		if err := e.export(ctx, spans[:n]); err != nil {
+			e.requeue(spans)
+			return err
+		}
+		spans = spans[n:]
+	}
+	return nil
+}
+
+// requeue puts spans that failed to export back in front of the ones queued
+// since. Spans beyond the queue limit are dropped, newest first.
+func (e *Exporter) requeue(spans []*Span) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	queued := append(spans, e.pending...)
+	if len(queued) > maxQueueSize {
+		e.dropped += len(queued) - maxQueueSize
+		queued = queued[:maxQueueSize]
+	}
+	e.pending = queued
+}
+
+// Shutdown stops the flush loop after exporting the remaining spans
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	select {
+	case <-e.stopCh:
+		return nil
+	default:
+		close(e.stopCh)
+	}
+
+	select {
+	case <-e.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// export posts one batch of spans to the collector
+func (e *Exporter) export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export %d span(s): %w", len(spans), err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to export %d span(s): collector returned %s", len(spans), resp.Status)
+	}
+	return nil
+}
+
+// ==========================
+// OTLP JSON Encoding
+// ==========================
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []spanJSON `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type spanJSON struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code"` // 0 = unset, 1 = ok, 2 = error
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // int64 values are strings in OTLP JSON
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *Exporter) request(spans []*Span) exportRequest {
+	encoded := make([]spanJSON, 0, len(spans))
+	for _, s := range spans {
+		encoded = append(encoded, encodeSpan(s))
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: []keyValue{attribute("service.name", e.ServiceName)}},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+func encodeSpan(s *Span) spanJSON {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	encoded := spanJSON{
+		TraceID:           hex.EncodeToString(s.TraceID[:]),
+		SpanID:            hex.EncodeToString(s.SpanID[:]),
+		Name:              s.Name,
+		Kind:              s.Kind,
+		StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		Status:            status{Code: 1},
+	}
+	if s.ParentID != [8]byte{} {
+		encoded.ParentSpanID = hex.EncodeToString(s.ParentID[:])
+	}
+	if s.Failed {
+		encoded.Status = status{Code: 2, Message: s.Message}
+	}
+	keys := make([]string, 0, len(s.Attributes))
+	for key := range s.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		encoded.Attributes = append(encoded.Attributes, attribute(key, s.Attributes[key]))
+	}
+	return encoded
+}
+
+func attribute(key string, value interface{}) keyValue {
+	var v anyValue
+	switch value := value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.Itoa(value)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return keyValue{Key: key, Value: v}
+}
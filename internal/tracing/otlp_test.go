@@ -0,0 +1,202 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is an OTLP/HTTP collector that records the decoded export requests
+type collector struct {
+	mutex    sync.Mutex
+	requests []exportRequest
+	headers  []http.Header
+	fail     bool // Answer 503 instead of accepting the spans
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if r.URL.Path != tracesPath || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	if c.fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var req exportRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header.Clone())
+}
+
+func (c *collector) spans() []spanJSON {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var spans []spanJSON
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+// newTestExporter returns an exporter without its flush loop, so tests flush by hand
+func newTestExporter(t *testing.T, c *collector) *Exporter {
+	t.Helper()
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	return &Exporter{
+		Endpoint:    TracesEndpoint(server.URL),
+		ServiceName: "apiwatcher-test",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		Client:      server.Client(),
+	}
+}
+
+func TestExportPayload(t *testing.T) {
+	c := &collector{}
+	e := newTestExporter(t, c)
+
+	start := time.Unix(1700000000, 0)
+	root := newSpan(e, nil, "check", KindInternal, start)
+	root.SetAttribute("url", "https://example.com")
+	root.SetAttribute("status", 200)
+	child := root.Child("GET https://example.com/api", KindClient, start.Add(10*time.Millisecond))
+	child.SetAttribute("cached", true)
+	child.SetError("timeout")
+	child.FinishAt(start.Add(50 * time.Millisecond))
+	root.FinishAt(start.Add(time.Second))
+
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(c.requests) != 1 {
+		t.Fatalf("got %d export requests, want 1", len(c.requests))
+	}
+	if got := c.headers[0].Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if got := c.headers[0].Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want the configured header", got)
+	}
+
+	req := c.requests[0]
+	if len(req.ResourceSpans) != 1 {
+		t.Fatalf("got %d resourceSpans, want 1", len(req.ResourceSpans))
+	}
+	attrs := req.ResourceSpans[0].Resource.Attributes
+	if len(attrs) != 1 || attrs[0].Key != "service.name" || attrs[0].Value.StringValue == nil || *attrs[0].Value.StringValue != "apiwatcher-test" {
+		t.Errorf("resource attributes = %+v, want service.name=apiwatcher-test", attrs)
+	}
+	if name := req.ResourceSpans[0].ScopeSpans[0].Scope.Name; name != scopeName {
+		t.Errorf("scope name = %q, want %q", name, scopeName)
+	}
+
+	spans := c.spans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	byName := map[string]spanJSON{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	gotRoot, gotChild := byName["check"], byName["GET https://example.com/api"]
+
+	if len(gotRoot.TraceID) != 32 || len(gotRoot.SpanID) != 16 {
+		t.Errorf("root IDs = %q/%q, want 16 and 8 hex encoded bytes", gotRoot.TraceID, gotRoot.SpanID)
+	}
+	if gotRoot.ParentSpanID != "" {
+		t.Errorf("root parentSpanId = %q, want none", gotRoot.ParentSpanID)
+	}
+	if gotChild.TraceID != gotRoot.TraceID {
+		t.Errorf("child traceId = %q, want the root's %q", gotChild.TraceID, gotRoot.TraceID)
+	}
+	if gotChild.ParentSpanID != gotRoot.SpanID {
+		t.Errorf("child parentSpanId = %q, want the root's spanId %q", gotChild.ParentSpanID, gotRoot.SpanID)
+	}
+	if gotChild.SpanID == gotRoot.SpanID {
+		t.Errorf("child and root share spanId %q", gotChild.SpanID)
+	}
+
+	if gotRoot.Kind != KindInternal || gotChild.Kind != KindClient {
+		t.Errorf("kinds = %d/%d, want %d/%d", gotRoot.Kind, gotChild.Kind, KindInternal, KindClient)
+	}
+	if gotRoot.StartTimeUnixNano != "1700000000000000000" || gotRoot.EndTimeUnixNano != "1700000001000000000" {
+		t.Errorf("root times = %s-%s", gotRoot.StartTimeUnixNano, gotRoot.EndTimeUnixNano)
+	}
+	if gotRoot.Status.Code != 1 || gotChild.Status.Code != 2 || gotChild.Status.Message != "timeout" {
+		t.Errorf("statuses = %+v/%+v, want ok and error(timeout)", gotRoot.Status, gotChild.Status)
+	}
+
+	// Attributes are sorted by key, integers are strings in OTLP JSON
+	if len(gotRoot.Attributes) != 2 || gotRoot.Attributes[0].Key != "status" || gotRoot.Attributes[1].Key != "url" {
+		t.Fatalf("root attributes = %+v, want status and url", gotRoot.Attributes)
+	}
+	if v := gotRoot.Attributes[0].Value.IntValue; v == nil || *v != "200" {
+		t.Errorf("status attribute = %+v, want intValue \"200\"", gotRoot.Attributes[0].Value)
+	}
+	if v := gotChild.Attributes[0].Value.BoolValue; len(gotChild.Attributes) != 1 || v == nil || !*v {
+		t.Errorf("child attributes = %+v, want cached=true", gotChild.Attributes)
+	}
+}
+
+func TestFlushKeepsFailedBatch(t *testing.T) {
+	c := &collector{fail: true}
+	e := newTestExporter(t, c)
+
+	for range maxBatchSize + 10 {
+		newSpan(e, nil, "check", KindInternal, time.Now()).Finish()
+	}
+	if err := e.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded against a failing collector")
+	}
+	if len(e.pending) != maxBatchSize+10 {
+		t.Fatalf("%d spans queued after the failed flush, want %d", len(e.pending), maxBatchSize+10)
+	}
+
+	c.mutex.Lock()
+	c.fail = false
+	c.mutex.Unlock()
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := len(c.spans()); got != maxBatchSize+10 {
+		t.Errorf("collector received %d spans, want %d", got, maxBatchSize+10)
+	}
+	if len(e.pending) != 0 {
+		t.Errorf("%d spans still queued", len(e.pending))
+	}
+}
+
+func TestRequeueRespectsQueueLimit(t *testing.T) {
+	e := &Exporter{}
+	failed := make([]*Span, maxQueueSize)
+	for i := range failed {
+		failed[i] = &Span{Name: "failed"}
+	}
+	e.pending = []*Span{{Name: "newer"}}
+
+	e.requeue(failed)
+	if len(e.pending) != maxQueueSize || e.pending[0].Name != "failed" {
+		t.Fatalf("queue = %d spans starting with %q, want %d failed spans first", len(e.pending), e.pending[0].Name, maxQueueSize)
+	}
+	if e.dropped != 1 {
+		t.Errorf("dropped = %d, want 1", e.dropped)
+	}
+}
@@ -0,0 +1,172 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// Span kinds (OTLP values)
+const (
+	KindInternal = 1
+	KindClient   = 3
+)
+
+// Span is a single timed operation of a trace.
+// All methods are safe to call on a nil span, which is what Start returns while
+// tracing is disabled, so callers never need to check.
+type Span struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	ParentID   [8]byte // Zero for root spans
+	Name       string
+	Kind       int
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]interface{}
+	Failed     bool   // Span status is error
+	Message    string // Status message
+
+	exporter *Exporter
+	mutex    sync.Mutex
+	ended    bool
+}
+
+type spanKey struct{}
+
+// ==========================
+// Global Exporter
+// ==========================
+
+var (
+	defaultExporter *Exporter
+	exporterMutex   sync.RWMutex
+)
+
+// SetExporter sets the exporter spans are sent to (nil disables tracing)
+func SetExporter(e *Exporter) {
+	exporterMutex.Lock()
+	defer exporterMutex.Unlock()
+	defaultExporter = e
+}
+
+// Enabled reports whether spans are being exported
+func Enabled() bool {
+	exporterMutex.RLock()
+	defer exporterMutex.RUnlock()
+	return defaultExporter != nil
+}
+
+// ==========================
+// Span Lifecycle
+// ==========================
+
+// Start starts a span as a child of the span in ctx (or a new trace) and returns
+// a context carrying it. While tracing is disabled it returns ctx and a nil span.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	exporterMutex.RLock()
+	exporter := defaultExporter
+	exporterMutex.RUnlock()
+	if exporter == nil {
+		return ctx, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	span := newSpan(exporter, SpanFromContext(ctx), name, KindInternal, time.Now())
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Child starts a child span with an explicit start time (e.g. one reported by the browser)
+func (s *Span) Child(name string, kind int, start time.Time) *Span {
+	if s == nil {
+		return nil
+	}
+	return newSpan(s.exporter, s, name, kind, start)
+}
+
+func newSpan(exporter *Exporter, parent *Span, name string, kind int, start time.Time) *Span {
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		Start:      start,
+		Attributes: make(map[string]interface{}),
+		exporter:   exporter,
+	}
+	if parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		randomBytes(span.TraceID[:])
+	}
+	randomBytes(span.SpanID[:])
+	return span
+}
+
+// SetAttribute sets a string, bool, integer or float attribute
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Attributes[key] = value
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Failed = true
+	s.Message = message
+}
+
+// Finish ends the span now and queues it for export
+func (s *Span) Finish() {
+	s.FinishAt(time.Now())
+}
+
+// FinishAt ends the span at the given time and queues it for export.
+// Only the first call has an effect.
+func (s *Span) FinishAt(end time.Time) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	if end.Before(s.Start) {
+		end = s.Start
+	}
+	s.End = end
+	s.mutex.Unlock()
+
+	s.exporter.enqueue(s)
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to a time based ID rather than an all-zero (invalid) one
+		now := time.Now().UnixNano()
+		for i := range b {
+			b[i] = byte(now >> (8 * (i % 8)))
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Strategy kinds
+const (
+	KindNetworkIdle = "network_idle" // No more than MaxInflight requests in flight for IdleMs
+	KindSelector    = "selector"     // An element matching Selector is visible
+	KindResponse    = "response"     // A response whose URL matches Pattern was received (with Status, if set)
+	KindFixed       = "fixed"        // Sleep for DelayMs (the old behaviour)
+)
+
+const (
+	DefaultIdle    = 500 * time.Millisecond
+	DefaultTimeout = 30 * time.Second
+	pollInterval   = 50 * time.Millisecond
+)
+
+// Strategy describes what to wait for before a page counts as ready
+type Strategy struct {
+	Kind        string `json:"kind"`
+	IdleMs      int    `json:"idle_ms,omitempty"`      // KindNetworkIdle: quiet period (default 500)
+	MaxInflight int    `json:"max_inflight,omitempty"` // KindNetworkIdle: requests tolerated in flight, e.g. long polling (default 0)
+	Selector    string `json:"selector,omitempty"`     // KindSelector: CSS selector
+	Pattern     string `json:"pattern,omitempty"`      // KindResponse: URL regex
+	Status      int    `json:"status,omitempty"`       // KindResponse: expected status (0 = any)
+	DelayMs     int    `json:"delay_ms,omitempty"`     // KindFixed: delay
+	TimeoutMs   int    `json:"timeout_ms,omitempty"`   // Give up after (default 30000)
+}
+
+// NetworkIdle returns a network idle strategy
+func NetworkIdle(idle, timeout time.Duration) *Strategy {
+	return &Strategy{
+		Kind:      KindNetworkIdle,
+		IdleMs:    int(idle / time.Millisecond),
+		TimeoutMs: int(timeout / time.Millisecond),
+	}
+}
+
+// Validate checks that the strategy has the fields its kind needs
+func (s *Strategy) Validate() error {
+	if s.IdleMs < 0 || s.DelayMs < 0 || s.TimeoutMs < 0 || s.MaxInflight < 0 {
+		return fmt.Errorf("wait durations and max_inflight must not be negative")
+	}
+	switch s.Kind {
+	case KindNetworkIdle:
+	case KindSelector:
+		if s.Selector == "" {
+			return fmt.Errorf("selector wait requires a selector")
+		}
+	case KindResponse:
+		if s.Pattern == "" {
+			return fmt.Errorf("response wait requires a pattern")
+		}
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("invalid response pattern %q: %w", s.Pattern, err)
+		}
+	case KindFixed:
+		if s.DelayMs == 0 {
+			return fmt.Errorf("fixed wait requires delay_ms")
+		}
+	default:
+		return fmt.Errorf("unknown wait kind: %q", s.Kind)
+	}
+	return nil
+}
+
+// Timeout returns how long the strategy waits before giving up
+func (s *Strategy) Timeout() time.Duration {
+	if s.TimeoutMs > 0 {
+		return time.Duration(s.TimeoutMs) * time.Millisecond
+	}
+	return DefaultTimeout
+}
+
+func (s *Strategy) idle() time.Duration {
+	if s.IdleMs > 0 {
+		return time.Duration(s.IdleMs) * time.Millisecond
+	}
+	return DefaultIdle
+}
+
+// String describes the strategy for logs
+func (s *Strategy) String() string {
+	switch s.Kind {
+	case KindNetworkIdle:
+		return fmt.Sprintf("network idle for %v", s.idle())
+	case KindSelector:
+		return fmt.Sprintf("selector %s", s.Selector)
+	case KindResponse:
+		if s.Status != 0 {
+			return fmt.Sprintf("response %s with status %d", s.Pattern, s.Status)
+		}
+		return fmt.Sprintf("response %s", s.Pattern)
+	case KindFixed:
+		return fmt.Sprintf("%dms", s.DelayMs)
+	}
+	return s.Kind
+}
+
+// ==========================
+// Network Tracking
+// ==========================
+
+// Tracker follows the requests of a browser session so strategies can wait on
+// network activity. Feed it every chromedp event with Handle.
+type Tracker struct {
+	mutex        sync.Mutex
+	inflight     map[network.RequestID]bool
+	lastActivity time.Time
+	responses    []response
+}
+
+type response struct {
+	URL    string
+	Status int
+}
+
+// NewTracker creates a tracker with no requests in flight
+func NewTracker() *Tracker {
+	return &Tracker{inflight: make(map[network.RequestID]bool), lastActivity: time.Now()}
+}
+
+// Handle updates the tracker from a chromedp event; other events are ignored
+func (t *Tracker) Handle(ev interface{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch ev := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		// Redirects reuse the request ID and stay in flight
+		t.inflight[ev.RequestID] = true
+	case *network.EventResponseReceived:
+		if ev.Response != nil {
+			t.responses = append(t.responses, response{URL: ev.Response.URL, Status: int(ev.Response.Status)})
+		}
+	case *network.EventLoadingFinished:
+		delete(t.inflight, ev.RequestID)
+	case *network.EventLoadingFailed:
+		delete(t.inflight, ev.RequestID)
+	default:
+		return
+	}
+	t.lastActivity = time.Now()
+}
+
+// Mark returns a position in the received responses. A response wait given a
+// mark only matches responses received after it.
+func (t *Tracker) Mark() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.responses)
+}
+
+// Wait blocks until the strategy is satisfied, its timeout expires or ctx is done.
+// ctx must be a chromedp context for selector waits.
+func (t *Tracker) Wait(ctx context.Context, s *Strategy, mark int) error {
+	if s == nil {
+		return nil
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, s.Timeout())
+	defer cancel()
+
+	var err error
+	switch s.Kind {
+	case KindFixed:
+		err = chromedp.Run(waitCtx, chromedp.Sleep(time.Duration(s.DelayMs)*time.Millisecond))
+	case KindSelector:
+		err = chromedp.Run(waitCtx, chromedp.WaitVisible(s.Selector, chromedp.ByQuery))
+	case KindNetworkIdle:
+		err = poll(waitCtx, func() bool { return t.idleFor(s.idle(), s.MaxInflight) })
+	case KindResponse:
+		pattern := regexp.MustCompile(s.Pattern)
+		err = poll(waitCtx, func() bool { return t.received(pattern, s.Status, mark) })
+	}
+	if err != nil && waitCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		return fmt.Errorf("timed out after %v waiting for %s", s.Timeout(), s)
+	}
+	return err
+}
+
+// idleFor reports whether at most maxInflight requests have been in flight for idle
+func (t *Tracker) idleFor(idle time.Duration, maxInflight int) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.inflight) > maxInflight {
+		return false
+	}
+	return time.Since(t.lastActivity) >= idle
+}
+
+// received reports whether a matching response was received after mark
+func (t *Tracker) received(pattern *regexp.Regexp, status int, mark int) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, r := range t.responses[min(mark, len(t.responses)):] {
+		if pattern.MatchString(r.URL) && (status == 0 || r.Status == status) {
+			return true
+		}
+	}
+	return false
+}
+
+// poll calls done until it returns true or ctx is done
+func poll(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}